@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"create-go-app.dev/fsys"
+	"create-go-app.dev/gotools"
+	"create-go-app.dev/manifest"
+	"create-go-app.dev/merge"
+
+	"github.com/fatih/color"
+)
+
+// addChange is a file add writes.
+type addChange struct {
+	file manifest.File
+	// 'create', 'update' or 'merge'.
+	action   string
+	contents []byte
+	// The component's version of the file, saved as the base of upgrades.
+	theirs []byte
+}
+
+// add runs 'create-go-app add <component>...', which adds components to the
+// project in -dir. The project is rendered with and without them, the files
+// only the new components have are created and the wiring that changed, e.g.
+// in docker-compose.yml, .env and cmd/main.go, is merged into the project's
+// files.
+func add(args []string) error {
+	flags := flag.NewFlagSet("add", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "root directory of the generated project")
+	force := flags.Bool("force", false, "overwrite files you modified when your changes conflict with the component's")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: create-go-app add [flags] <component>...\n")
+		fmt.Printf("  To add Redis and the Playwright suite to the project in the current directory run:\n")
+		fmt.Printf("  create-go-app add redis node playwright\n")
+		flags.PrintDefaults()
+	}
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("create-go-app: missing component name")
+	}
+
+	m, err := manifest.Read(*dir)
+	if err != nil {
+		return err
+	}
+
+	pt, src, err := manifestType(m)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range flags.Args() {
+		if slices.Contains(m.Options.Components, name) {
+			return fmt.Errorf("create-go-app: the project already has the '%s' component", name)
+		}
+	}
+
+	components, err := pt.components.Select(slices.Concat(m.Options.Components, flags.Args()), nil)
+	if err != nil {
+		return err
+	}
+
+	// Imports are rewritten to the module path in go.mod, which the user
+	// may have changed since generating.
+	module := m.Options.Module
+	gomod, err := os.ReadFile(filepath.Join(*dir, pt.moduleDir, "go.mod"))
+	if err == nil {
+		module, err = gotools.ModulePath(gomod)
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	_, beforeDir, err := renderProject(ctx, src, pt, m.Options, module, m.Options.Components)
+	if beforeDir != "" {
+		defer os.RemoveAll(beforeDir)
+	}
+	if err != nil {
+		return err
+	}
+
+	after, afterDir, err := renderProject(ctx, src, pt, m.Options, module, components)
+	if afterDir != "" {
+		defer os.RemoveAll(afterDir)
+	}
+	if err != nil {
+		return err
+	}
+
+	changes, err := addChanges(*dir, beforeDir, after, afterDir, *force)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		dst := filepath.Join(*dir, filepath.FromSlash(c.file.Path))
+
+		err := os.MkdirAll(filepath.Dir(dst), fsys.DirPerm)
+		if err != nil {
+			return err
+		}
+
+		// Existing files keep their permissions.
+		err = os.WriteFile(dst, c.contents, fsys.Mode(c.file.Path, c.contents))
+		if err != nil {
+			return err
+		}
+
+		err = manifest.WriteBase(fsys.OS(*dir), c.file.Path, c.theirs)
+		if err != nil {
+			return err
+		}
+		m.Add(c.file)
+
+		label := color.CyanString(c.action)
+		if c.action == "create" {
+			label = color.GreenString(c.action)
+		}
+		fmt.Fprintf(color.Output, "%s %s\n", label, c.file.Path)
+	}
+
+	m.Options.Components = components
+	m.Generator.Version = generatorVersion()
+	m.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+
+	return m.Write(*dir)
+}
+
+// addChanges returns the files to write to the project in dir so that it
+// matches the after render instead of the before one. Nothing is written
+// when a modified file conflicts, unless force is set.
+func addChanges(dir string, beforeDir string, after *manifest.Manifest, afterDir string, force bool) ([]addChange, error) {
+	labels := merge.Labels{Ours: "yours", Base: "without the component", Theirs: "with the component"}
+
+	var changes []addChange
+	var conflicts []string
+
+	for _, f := range after.Files {
+		theirs, err := os.ReadFile(filepath.Join(afterDir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			return nil, err
+		}
+
+		base, err := os.ReadFile(filepath.Join(beforeDir, filepath.FromSlash(f.Path)))
+		hasBase := err == nil
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if hasBase && bytes.Equal(base, theirs) {
+			// Not affected by the new components.
+			continue
+		}
+
+		c := addChange{file: f, theirs: theirs}
+
+		ours, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			c.action, c.contents = "create", theirs
+		case err != nil:
+			return nil, err
+		case bytes.Equal(ours, theirs):
+			continue
+		case !hasBase:
+			// A file of the component that is already in the project.
+			if !force {
+				conflicts = append(conflicts, f.Path)
+				continue
+			}
+			c.action, c.contents = "update", theirs
+		case bytes.Equal(ours, base):
+			c.action, c.contents = "update", theirs
+		default:
+			// Modified since generating, e.g. by the user or by 'generate
+			// resource'.
+			res := merge.Three(base, ours, theirs, labels)
+			switch {
+			case res.Conflicts == 0:
+				c.action, c.contents = "merge", res.Merged
+			case force:
+				c.action, c.contents = "update", theirs
+			default:
+				conflicts = append(conflicts, f.Path)
+				continue
+			}
+		}
+
+		changes = append(changes, c)
+	}
+
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("create-go-app: your changes to %s conflict with the component's, pass -force to overwrite them", strings.Join(conflicts, ", "))
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"create-go-app.dev/manifest"
+)
+
+func TestAddChanges(t *testing.T) {
+	compose := "services:\n  app:\n    image: app\n\n  postgres:\n    image: postgres\n"
+	withRedis := compose + "\n  redis:\n    image: redis\n"
+
+	// The project rendered without and with the redis component.
+	before := map[string]string{
+		"main.go":            "package main\n",
+		"docker-compose.yml": compose,
+	}
+	after := map[string]string{
+		"main.go":            "package main\n",
+		"docker-compose.yml": withRedis,
+		"redis/redis.go":     "package redis\n",
+	}
+
+	tests := []struct {
+		title string
+		// Files in the project.
+		project map[string]string
+		force   bool
+		// Action and contents by path.
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			title:   "Unmodified project",
+			project: before,
+			want: map[string]string{
+				"docker-compose.yml": "update\n" + withRedis,
+				"redis/redis.go":     "create\npackage redis\n",
+			},
+		},
+		{
+			title: "Merge into a modified file",
+			project: map[string]string{
+				"main.go":            "package main\n",
+				"docker-compose.yml": "services:\n  app:\n    image: my-app\n\n  postgres:\n    image: postgres\n",
+			},
+			want: map[string]string{
+				"docker-compose.yml": "merge\nservices:\n  app:\n    image: my-app\n\n  postgres:\n    image: postgres\n\n  redis:\n    image: redis\n",
+				"redis/redis.go":     "create\npackage redis\n",
+			},
+		},
+		{
+			title: "Already up to date",
+			project: map[string]string{
+				"main.go":            "package main\n// mine\n",
+				"docker-compose.yml": withRedis,
+				"redis/redis.go":     "package redis\n",
+			},
+			want: map[string]string{},
+		},
+		{
+			title: "Conflict",
+			project: map[string]string{
+				"docker-compose.yml": compose + "\n  cache:\n    image: memcached\n",
+				"redis/redis.go":     "package mine\n",
+			},
+			wantErr: "docker-compose.yml, redis/redis.go",
+		},
+		{
+			title: "Conflict with -force",
+			project: map[string]string{
+				"docker-compose.yml": compose + "\n  cache:\n    image: memcached\n",
+				"redis/redis.go":     "package mine\n",
+			},
+			force: true,
+			want: map[string]string{
+				"docker-compose.yml": "update\n" + withRedis,
+				"redis/redis.go":     "update\npackage redis\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "project")
+			writeFiles(t, dir, tt.project)
+			beforeDir := filepath.Join(t.TempDir(), "before")
+			writeFiles(t, beforeDir, before)
+			afterDir := filepath.Join(t.TempDir(), "after")
+			writeFiles(t, afterDir, after)
+
+			m := &manifest.Manifest{}
+			for _, path := range slices.Sorted(maps.Keys(after)) {
+				m.Add(manifest.File{Path: path})
+			}
+			err := m.Hash(os.DirFS(afterDir))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			changes, err := addChanges(dir, beforeDir, m, afterDir, tt.force)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want one about %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := map[string]string{}
+			for _, c := range changes {
+				got[c.file.Path] = c.action + "\n" + string(c.contents)
+				if string(c.theirs) != after[c.file.Path] {
+					t.Errorf("%s: base = %q, want the component's %q", c.file.Path, c.theirs, after[c.file.Path])
+				}
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("changes = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package component models the optional parts of a template, e.g. Postgres,
+// and which embedded paths each one owns.
+package component
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Component is an optional part of a template.
+type Component struct {
+	Name    string `yaml:"name"`
+	Summary string `yaml:"summary"`
+	// Paths, relative to the template's root, that are only emitted when the
+	// component is selected. A directory includes everything below it.
+	Paths []string `yaml:"paths"`
+	// Names of the components this one depends on.
+	Requires []string `yaml:"requires"`
+}
+
+// Set is every component a template supports, in display order.
+type Set []Component
+
+// Names returns the name of every component in the set.
+func (s Set) Names() []string {
+	names := make([]string, 0, len(s))
+	for _, c := range s {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// Lookup returns the component with the given name.
+func (s Set) Lookup(name string) (Component, bool) {
+	for _, c := range s {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Component{}, false
+}
+
+// Select returns the selected component names in set order. When with is nil
+// every component is a candidate, otherwise only the ones in with. Components
+// in without are then removed.
+func (s Set) Select(with []string, without []string) ([]string, error) {
+	for _, name := range slices.Concat(with, without) {
+		if _, ok := s.Lookup(name); !ok {
+			return nil, fmt.Errorf("create-go-app: unknown component '%s', expected one of: %s", name, strings.Join(s.Names(), ", "))
+		}
+	}
+
+	var selected []string
+	for _, c := range s {
+		if with != nil && !slices.Contains(with, c.Name) {
+			continue
+		}
+		if slices.Contains(without, c.Name) {
+			continue
+		}
+		selected = append(selected, c.Name)
+	}
+
+	// Components whose requirements are left out with without, e.g.
+	// playwright without node, are named in the error with the fix.
+	var dependents []string
+	var first error
+	for _, name := range selected {
+		c, _ := s.Lookup(name)
+		for _, req := range c.Requires {
+			switch {
+			case slices.Contains(selected, req):
+			case slices.Contains(without, req):
+				if first == nil {
+					first = fmt.Errorf("create-go-app: component '%s' requires '%s'", name, req)
+				}
+				if !slices.Contains(dependents, name) {
+					dependents = append(dependents, name)
+				}
+			default:
+				return nil, fmt.Errorf("create-go-app: component '%s' requires '%s'", name, req)
+			}
+		}
+	}
+
+	if first != nil {
+		return nil, fmt.Errorf("%w, leave out the components that need it too with -without=%s", first, strings.Join(slices.Concat(without, dependents), ","))
+	}
+
+	return selected, nil
+}
+
+// Excluded reports whether rel, a slash separated path relative to the
+// template's root, belongs to a component that isn't selected.
+func (s Set) Excluded(rel string, selected []string) bool {
+	for _, c := range s {
+		if slices.Contains(selected, c.Name) {
+			continue
+		}
+		for _, p := range c.Paths {
+			if rel == p || strings.HasPrefix(rel, p+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Parse splits a comma separated list of names, e.g. the value of '-with'.
+// 'none' is an explicit empty list.
+func Parse(list string) []string {
+	names := []string{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || name == "none" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,114 @@
+package component
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testSet = Set{
+	{Name: "postgres", Paths: []string{"postgres", "go/postgres"}},
+	{Name: "swagger", Paths: []string{"swagger.yaml.tmpl"}},
+	{Name: "node", Paths: []string{"node"}},
+	{Name: "playwright", Paths: []string{"playwright"}, Requires: []string{"node"}},
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		title   string
+		with    []string
+		without []string
+		want    []string
+		wantErr string
+	}{
+		{
+			title: "Defaults to all",
+			want:  []string{"postgres", "swagger", "node", "playwright"},
+		},
+		{
+			title: "With keeps set order",
+			with:  []string{"swagger", "postgres"},
+			want:  []string{"postgres", "swagger"},
+		},
+		{
+			title:   "Without",
+			without: []string{"swagger"},
+			want:    []string{"postgres", "node", "playwright"},
+		},
+		{
+			title: "Explicitly none",
+			with:  []string{},
+			want:  nil,
+		},
+		{
+			title:   "Unknown component",
+			with:    []string{"mysql"},
+			wantErr: "unknown component 'mysql'",
+		},
+		{
+			title:   "Missing requirement",
+			with:    []string{"playwright"},
+			wantErr: "component 'playwright' requires 'node'",
+		},
+		{
+			title:   "Without a requirement",
+			without: []string{"node"},
+			wantErr: "-without=node,playwright",
+		},
+		{
+			title:   "Without a requirement and its dependents",
+			without: []string{"node", "playwright"},
+			want:    []string{"postgres", "swagger"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got, err := testSet.Select(tt.with, tt.without)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got = %v, want = %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExcluded(t *testing.T) {
+	selected := []string{"swagger"}
+
+	tests := map[string]bool{
+		"postgres":              true,
+		"postgres/initdb.sql":   true,
+		"go/postgres/things.go": true,
+		"go/postgresql.go":      false,
+		"swagger.yaml.tmpl":     false,
+		"node/src/server.mts":   true,
+		"go/cmd/main.go.tmpl":   false,
+	}
+
+	for rel, want := range tests {
+		if got := testSet.Excluded(rel, selected); got != want {
+			t.Errorf("Excluded(%q) = %v, want = %v", rel, got, want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	got := Parse(" Postgres, ,redis,")
+	if !slices.Equal(got, []string{"postgres", "redis"}) {
+		t.Errorf("got = %v", got)
+	}
+
+	if got := Parse("none"); got == nil || len(got) != 0 {
+		t.Errorf("got = %#v, want = empty non-nil", got)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"create-go-app.dev/config"
+	"create-go-app.dev/license"
+	"create-go-app.dev/tmpl"
+)
+
+// settings are the defaults from the config files and the environment, see
+// applyConfig.
+var settings = &config.Config{Sources: map[string]string{}}
+
+// configCommand runs 'create-go-app config show', which prints the defaults
+// new apps are generated with and where each comes from.
+func configCommand(args []string) error {
+	if len(args) != 1 || args[0] != "show" {
+		return fmt.Errorf("create-go-app: usage: create-go-app config show")
+	}
+
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+
+	user, err := config.UserPath()
+	if err != nil {
+		user = "none, " + err.Error()
+	}
+	project, err := config.ProjectPath(".")
+	if err != nil {
+		return err
+	}
+	if project == "" {
+		project = fmt.Sprintf("none, no %s in this directory or its parents", config.ProjectName)
+	}
+	fmt.Printf("User config:    %s\n", user)
+	fmt.Printf("Project config: %s\n\n", project)
+
+	// Built-in defaults of the keys that aren't set.
+	defaults := map[string]string{
+		config.KeyModulePrefix: "none",
+		config.KeyComponents:   "all",
+		config.KeyLicense:      license.MIT,
+		config.KeyAuthor:       "git config user.name",
+		config.KeyGoVersion:    DEFAULT_GO_VERSION,
+		config.KeyTemplate:     "none",
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, key := range config.Keys {
+		value, ok := cfg.Get(key)
+		source := cfg.Sources[key]
+		if !ok {
+			value, source = defaults[key], "default"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", key, value, source)
+	}
+	return tw.Flush()
+}
+
+// applyConfig loads the defaults from the config files and the environment
+// into settings, and sets the flags the user didn't set to them. Flags take
+// precedence, then environment variables, then the project's config file,
+// then the user's. The template only applies without -type, which picks an
+// embedded type instead.
+func applyConfig() error {
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	if cfg.License != "" && !set["license"] {
+		_, err = license.Parse(cfg.License)
+		if err != nil {
+			return fmt.Errorf("%w, set in %s", err, cfg.Sources[config.KeyLicense])
+		}
+		*licenseFlag = cfg.License
+	}
+
+	if cfg.Author != "" && !set["author"] {
+		*authorFlag = cfg.Author
+	}
+
+	if cfg.GoVersion != "" && !set["go-version"] {
+		err = tmpl.CheckGoVersion(cfg.GoVersion)
+		if err != nil {
+			return fmt.Errorf("%w, set in %s", err, cfg.Sources[config.KeyGoVersion])
+		}
+		*goVersionFlag = cfg.GoVersion
+	}
+
+	if cfg.Template != "" && !set["template"] && !set["type"] {
+		*templateFlag = cfg.Template
+	}
+
+	settings = cfg
+	return nil
+}
@@ -0,0 +1,259 @@
+// Package config reads the generator's defaults from the user's config file,
+// the project's config file and environment variables, each overriding the
+// ones before it, and records where each value comes from.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"create-go-app.dev/component"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ProjectName is the project's config file, looked up in the working
+	// directory and its parents.
+	ProjectName = "create-go-app.yaml"
+	// UserName is the user's config file in create-go-app's config
+	// directory.
+	UserName = "config.yaml"
+)
+
+// Keys of the settings, as in the config files.
+const (
+	KeyModulePrefix = "modulePrefix"
+	KeyComponents   = "components"
+	KeyLicense      = "license"
+	KeyAuthor       = "author"
+	KeyGoVersion    = "goVersion"
+	KeyTemplate     = "template"
+)
+
+// Keys in the order they are shown.
+var Keys = []string{KeyModulePrefix, KeyComponents, KeyLicense, KeyAuthor, KeyGoVersion, KeyTemplate}
+
+// Env are the environment variables setting each key.
+var Env = map[string]string{
+	KeyModulePrefix: "CREATE_GO_APP_MODULE_PREFIX",
+	KeyComponents:   "CREATE_GO_APP_COMPONENTS",
+	KeyLicense:      "CREATE_GO_APP_LICENSE",
+	KeyAuthor:       "CREATE_GO_APP_AUTHOR",
+	KeyGoVersion:    "CREATE_GO_APP_GO_VERSION",
+	KeyTemplate:     "CREATE_GO_APP_TEMPLATE",
+}
+
+// Config is the generator's defaults. Empty values aren't set.
+type Config struct {
+	// Prefix of the module path, followed by the project's name when no
+	// module path is given, e.g. 'github.com/ourorg/'.
+	ModulePrefix string `yaml:"modulePrefix"`
+	// Components selected by default, nil when not set and empty for none.
+	Components []string `yaml:"components"`
+	License    string   `yaml:"license"`
+	// Copyright holder in the license, e.g. 'Jane Doe'.
+	Author string `yaml:"author"`
+	// Go version of the generated module, e.g. '1.23.5'.
+	GoVersion string `yaml:"goVersion"`
+	// Template directory or registered name to generate from.
+	Template string `yaml:"template"`
+
+	// Where the values that are set come from, by key, e.g. a file's path
+	// or '$CREATE_GO_APP_LICENSE'.
+	Sources map[string]string `yaml:"-"`
+	// Config files read, lowest precedence first.
+	Files []string `yaml:"-"`
+}
+
+// Parse decodes the contents of a config file. Unknown keys are an error, so
+// typos don't go unnoticed.
+func Parse(b []byte) (*Config, error) {
+	c := &Config{}
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	err := dec.Decode(c)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Read reads the config file at path, nil when it doesn't exist. A relative
+// template directory in the file is made relative to the file's directory.
+func Read(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("create-go-app: %s: %w", path, err)
+	}
+
+	if t := c.Template; isPath(t) && !filepath.IsAbs(t) {
+		c.Template = filepath.Join(filepath.Dir(path), t)
+	}
+	return c, nil
+}
+
+// isPath reports whether the template ref is a directory rather than a
+// registered name, see templates.Registry.Resolve.
+func isPath(ref string) bool {
+	return filepath.IsAbs(ref) || strings.HasPrefix(ref, ".") || strings.ContainsAny(ref, `/\`)
+}
+
+// UserPath returns where the user's config file is kept, e.g.
+// '~/.config/create-go-app/config.yaml' on Linux.
+func UserPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "create-go-app", UserName), nil
+}
+
+// ProjectPath returns the project's config file in dir or the closest of its
+// parents, or "" when there is none.
+func ProjectPath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		path := filepath.Join(dir, ProjectName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Load returns the defaults from the user's config file, the project's
+// config file for dir and the environment, in increasing precedence. Missing
+// files are skipped.
+func Load(dir string) (*Config, error) {
+	c := &Config{Sources: map[string]string{}}
+
+	var paths []string
+	// Without a config directory only the project's file is read.
+	if path, err := UserPath(); err == nil {
+		paths = append(paths, path)
+	}
+
+	path, err := ProjectPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		paths = append(paths, path)
+	}
+
+	for _, path := range paths {
+		f, err := Read(path)
+		if err != nil {
+			return nil, err
+		}
+		if f == nil {
+			continue
+		}
+
+		c.Files = append(c.Files, path)
+		c.merge(f, path)
+	}
+
+	for _, key := range Keys {
+		value := strings.TrimSpace(os.Getenv(Env[key]))
+		if value == "" {
+			continue
+		}
+		c.set(key, value)
+		c.Sources[key] = "$" + Env[key]
+	}
+
+	return c, nil
+}
+
+// merge sets the values set in o, recording source as their source.
+func (c *Config) merge(o *Config, source string) {
+	for _, key := range Keys {
+		value, ok := o.Get(key)
+		if !ok {
+			continue
+		}
+		c.set(key, value)
+		c.Sources[key] = source
+	}
+}
+
+// Get returns the value of key as it is given in the environment, e.g.
+// 'postgres,redis' or 'none' for the components, and whether it is set.
+func (c *Config) Get(key string) (string, bool) {
+	var value string
+	switch key {
+	case KeyModulePrefix:
+		value = c.ModulePrefix
+	case KeyComponents:
+		if c.Components == nil {
+			return "", false
+		}
+		if len(c.Components) == 0 {
+			return "none", true
+		}
+		return strings.Join(c.Components, ","), true
+	case KeyLicense:
+		value = c.License
+	case KeyAuthor:
+		value = c.Author
+	case KeyGoVersion:
+		value = c.GoVersion
+	case KeyTemplate:
+		value = c.Template
+	}
+	return value, value != ""
+}
+
+// set sets key to value as it is given in the environment.
+func (c *Config) set(key string, value string) {
+	switch key {
+	case KeyModulePrefix:
+		c.ModulePrefix = value
+	case KeyComponents:
+		c.Components = component.Parse(value)
+	case KeyLicense:
+		c.License = value
+	case KeyAuthor:
+		c.Author = value
+	case KeyGoVersion:
+		c.GoVersion = value
+	case KeyTemplate:
+		c.Template = value
+	}
+}
+
+// Module returns the module path of the project name with the module
+// prefix, or "" without a prefix.
+func (c *Config) Module(name string) string {
+	if c.ModulePrefix == "" {
+		return ""
+	}
+	return strings.TrimSuffix(c.ModulePrefix, "/") + "/" + name
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		title      string
+		file       string
+		components []string
+		wantErr    bool
+	}{
+		{
+			title:      "Components",
+			file:       "components: [postgres, redis]\n",
+			components: []string{"postgres", "redis"},
+		},
+		{
+			title:      "No components",
+			file:       "components: []\n",
+			components: []string{},
+		},
+		{
+			title: "Components not set",
+			file:  "license: MIT\n",
+		},
+		{
+			title: "Empty file",
+		},
+		{
+			title:   "Unknown key",
+			file:    "licence: MIT\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			c, err := Parse([]byte(tt.file))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if (c.Components == nil) != (tt.components == nil) || !slices.Equal(c.Components, tt.components) {
+				t.Errorf("Components = %#v, want %#v", c.Components, tt.components)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+	for _, key := range Keys {
+		t.Setenv(Env[key], "")
+	}
+
+	user := filepath.Join(home, "create-go-app", UserName)
+	write(t, user, "modulePrefix: github.com/me/\nlicense: Apache-2.0\nauthor: Jane Doe\ngoVersion: 1.22.1\n")
+
+	root := t.TempDir()
+	project := filepath.Join(root, ProjectName)
+	write(t, project, "modulePrefix: github.com/ourorg\ncomponents: []\ntemplate: ./templates/platform\n")
+
+	dir := filepath.Join(root, "services", "api")
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(Env[KeyGoVersion], "1.23.5")
+
+	c, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		key    string
+		value  string
+		source string
+	}{
+		{KeyModulePrefix, "github.com/ourorg", project},
+		{KeyComponents, "none", project},
+		{KeyLicense, "Apache-2.0", user},
+		{KeyAuthor, "Jane Doe", user},
+		{KeyGoVersion, "1.23.5", "$CREATE_GO_APP_GO_VERSION"},
+		{KeyTemplate, filepath.Join(root, "templates", "platform"), project},
+	}
+	for _, w := range want {
+		value, ok := c.Get(w.key)
+		if !ok || value != w.value || c.Sources[w.key] != w.source {
+			t.Errorf("%s = %q (%v) from %q, want %q from %q", w.key, value, ok, c.Sources[w.key], w.value, w.source)
+		}
+	}
+
+	if !slices.Equal(c.Files, []string{user, project}) {
+		t.Errorf("Files = %q", c.Files)
+	}
+
+	if got := c.Module("api"); got != "github.com/ourorg/api" {
+		t.Errorf("Module() = %q", got)
+	}
+}
+
+func TestLoadNothing(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, key := range Keys {
+		t.Setenv(Env[key], "")
+	}
+
+	c, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range Keys {
+		if value, ok := c.Get(key); ok {
+			t.Errorf("%s = %q, want not set", key, value)
+		}
+	}
+	if c.Module("api") != "" {
+		t.Errorf("Module() = %q, want none without a prefix", c.Module("api"))
+	}
+}
+
+func write(t *testing.T, path string, content string) {
+	t.Helper()
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"create-go-app.dev/manifest"
+	"create-go-app.dev/prompt"
+
+	"github.com/fatih/color"
+)
+
+// What happens to a generated file that already exists in the app's
+// directory with different contents.
+const (
+	conflictSkip      = "skip"      // keep the existing file
+	conflictOverwrite = "overwrite" // replace it
+	conflictPrompt    = "prompt"    // ask for each file
+	conflictFail      = "fail"      // write nothing and list the files
+)
+
+func checkConflictPolicy(policy string, interactive bool) error {
+	switch policy {
+	case conflictSkip, conflictOverwrite, conflictFail:
+		return nil
+	case conflictPrompt:
+		if !interactive {
+			return fmt.Errorf("create-go-app: -on-conflict=prompt needs an interactive session, not -yes")
+		}
+		return nil
+	}
+	return fmt.Errorf("create-go-app: invalid -on-conflict '%s', expected 'skip', 'overwrite', 'prompt' or 'fail'", policy)
+}
+
+// conflictingFiles returns the slash separated paths of the app's files
+// that exist in target with different contents. A missing target has none.
+// The manifest and the base copies belong to the generator and are always
+// replaced.
+func conflictingFiles(files fs.FS, target string) ([]string, error) {
+	var conflicts []string
+
+	err := fs.WalkDir(files, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		if name == manifest.Name || name == manifest.BaseIgnore || strings.HasPrefix(name, manifest.BaseDir+"/") {
+			return nil
+		}
+
+		dst := filepath.Join(target, filepath.FromSlash(name))
+		existing, err := os.ReadFile(dst)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			// E.g. a directory where the file goes.
+			return fmt.Errorf("create-go-app: can't write %s: %w", dst, err)
+		}
+
+		generated, err := fs.ReadFile(files, name)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(existing, generated) {
+			conflicts = append(conflicts, name)
+		}
+		return nil
+	})
+
+	return conflicts, err
+}
+
+// resolveConflicts applies policy to the conflicting files in target and
+// returns the ones to keep.
+func resolveConflicts(ctx context.Context, conflicts []string, target string, policy string) (map[string]bool, error) {
+	keep := map[string]bool{}
+	if len(conflicts) == 0 {
+		return keep, nil
+	}
+
+	switch policy {
+	case conflictFail:
+		return nil, fmt.Errorf("create-go-app: %d file(s) in %s would be overwritten, pass -on-conflict=skip, overwrite or prompt:\n\t%s", len(conflicts), target, strings.Join(conflicts, "\n\t"))
+	case conflictSkip:
+		for _, c := range conflicts {
+			keep[c] = true
+		}
+	case conflictPrompt:
+		all := ""
+		for _, c := range conflicts {
+			answer := all
+			for answer == "" {
+				a, err := prompt.LineContext(ctx, fmt.Sprintf("%s already exists, overwrite it? [y]es, [n]o, [a]ll, n[o]ne: ", c))
+				if err != nil {
+					return nil, err
+				}
+
+				switch strings.ToLower(a) {
+				case "y", "yes", "n", "no":
+					answer = strings.ToLower(a[:1])
+				case "a", "all":
+					answer, all = "y", "y"
+				case "o", "none":
+					answer, all = "n", "n"
+				}
+			}
+			keep[c] = answer == "n"
+		}
+	}
+
+	for _, c := range conflicts {
+		if keep[c] {
+			fmt.Fprintf(color.Output, "%s %s\n", color.YellowString("keep"), c)
+		} else {
+			fmt.Fprintf(color.Output, "%s %s\n", color.RedString("overwrite"), c)
+		}
+	}
+
+	return keep, nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"create-go-app.dev/prompt"
+)
+
+func TestCheckConflictPolicy(t *testing.T) {
+	tests := []struct {
+		policy      string
+		interactive bool
+		wantErr     bool
+	}{
+		{policy: "skip"},
+		{policy: "overwrite"},
+		{policy: "fail"},
+		{policy: "prompt", interactive: true},
+		{policy: "prompt", wantErr: true},
+		{policy: "merge", interactive: true, wantErr: true},
+		{policy: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := checkConflictPolicy(tt.policy, tt.interactive)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkConflictPolicy(%q, %v) = %v, want error %v", tt.policy, tt.interactive, err, tt.wantErr)
+		}
+	}
+}
+
+func TestConflictingFiles(t *testing.T) {
+	files := fstest.MapFS{
+		"main.go":                   {Data: []byte("generated")},
+		"README.md":                 {Data: []byte("generated")},
+		"internal/app/app.go":       {Data: []byte("generated")},
+		".create-go-app.json":       {Data: []byte("generated")},
+		".create-go-app/.gitignore": {Data: []byte("generated")},
+		".create-go-app/base/a.go":  {Data: []byte("generated")},
+	}
+
+	tests := []struct {
+		title string
+		// Files in the target, none when nil.
+		existing map[string]string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			title: "Missing target",
+		},
+		{
+			title:    "Unrelated files",
+			existing: map[string]string{"notes.txt": "mine"},
+		},
+		{
+			title:    "Same contents",
+			existing: map[string]string{"main.go": "generated"},
+		},
+		{
+			title:    "Different contents",
+			existing: map[string]string{"main.go": "mine", "README.md": "generated", "internal/app/app.go": "mine"},
+			want:     []string{"internal/app/app.go", "main.go"},
+		},
+		{
+			title: "Files of the generator",
+			existing: map[string]string{
+				".create-go-app.json":       "old",
+				".create-go-app/.gitignore": "old",
+				".create-go-app/base/a.go":  "old",
+			},
+		},
+		{
+			title:    "Directory in the way",
+			existing: map[string]string{"main.go/x": "mine"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			target := filepath.Join(t.TempDir(), "my-app")
+			if tt.existing != nil {
+				writeFiles(t, target, tt.existing)
+			}
+
+			got, err := conflictingFiles(files, target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("conflicts = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveConflicts(t *testing.T) {
+	conflicts := []string{"README.md", "main.go", "go.mod"}
+
+	tests := []struct {
+		title  string
+		policy string
+		// Answers to the prompts.
+		input   string
+		want    map[string]bool
+		wantErr bool
+	}{
+		{
+			title:  "Skip",
+			policy: conflictSkip,
+			want:   map[string]bool{"README.md": true, "main.go": true, "go.mod": true},
+		},
+		{
+			title:  "Overwrite",
+			policy: conflictOverwrite,
+			want:   map[string]bool{},
+		},
+		{
+			title:   "Fail",
+			policy:  conflictFail,
+			wantErr: true,
+		},
+		{
+			title:  "Prompt for each file",
+			policy: conflictPrompt,
+			input:  "n\nyes\nNo\n",
+			want:   map[string]bool{"README.md": true, "main.go": false, "go.mod": true},
+		},
+		{
+			title:  "Prompt again on invalid answers",
+			policy: conflictPrompt,
+			input:  "maybe\n\ny\nn\ny\n",
+			want:   map[string]bool{"README.md": false, "main.go": true, "go.mod": false},
+		},
+		{
+			title:  "Prompt, all",
+			policy: conflictPrompt,
+			input:  "n\na\n",
+			want:   map[string]bool{"README.md": true, "main.go": false, "go.mod": false},
+		},
+		{
+			title:  "Prompt, none",
+			policy: conflictPrompt,
+			input:  "none\n",
+			want:   map[string]bool{"README.md": true, "main.go": true, "go.mod": true},
+		},
+		{
+			title:   "Prompt without input",
+			policy:  conflictPrompt,
+			input:   "y\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			input, output := prompt.Input, prompt.Output
+			t.Cleanup(func() { prompt.Input, prompt.Output = input, output })
+			prompt.Input = bufio.NewReader(strings.NewReader(tt.input))
+			prompt.Output = &bytes.Buffer{}
+
+			keep, err := resolveConflicts(context.Background(), conflicts, "my-app", tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v\n%s", err, tt.wantErr, prompt.Output)
+			}
+			if !tt.wantErr && !maps.Equal(keep, tt.want) {
+				t.Errorf("keep = %v, want %v", keep, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveConflictsNone(t *testing.T) {
+	// Nothing to decide, not even with fail.
+	keep, err := resolveConflicts(context.Background(), nil, "my-app", conflictFail)
+	if err != nil || len(keep) != 0 {
+		t.Errorf("resolveConflicts() = %v, %v, want no files to keep", keep, err)
+	}
+}
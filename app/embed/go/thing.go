@@ -2,8 +2,6 @@ package thing
 
 import "time"
 
-// TODO: Generate services with user provided input instead of "thing".
-
 type Thing struct {
 	ID          int       `json:"id"`
 	Name        string    `json:"name"`
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/username/repo/config"
+)
+
+// App is passed to every command. It holds the program's output streams and
+// the loaded configuration.
+type App struct {
+	Name   string
+	Stdout io.Writer
+	Stderr io.Writer
+	Config config.Config
+}
+
+// Command is a single subcommand, e.g. 'version'.
+type Command struct {
+	Name    string
+	Summary string
+	Run     func(ctx context.Context, app *App, args []string) error
+}
+
+// Commands returns every registered subcommand. Add new commands here.
+func Commands() []Command {
+	return []Command{
+		{Name: "hello", Summary: "Print a greeting", Run: runHello},
+		{Name: "version", Summary: "Print the version", Run: runVersion},
+	}
+}
+
+// Run parses the global flags in args, loads the configuration and dispatches
+// to the named subcommand. args[0] is the program name. It returns the
+// process exit code.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	name := "app"
+	if len(args) > 0 {
+		name = filepath.Base(args[0])
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", "", "path to a JSON config file")
+	fs.Usage = func() {
+		usage(stderr, name, fs)
+	}
+
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	cmdName := fs.Arg(0)
+	if cmdName == "help" {
+		usage(stdout, name, fs)
+		return 0
+	}
+
+	cmd, ok := lookup(cmdName)
+	if !ok {
+		fmt.Fprintf(stderr, "%s: unknown command '%s'\n", name, cmdName)
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return 1
+	}
+
+	app := &App{
+		Name:   name,
+		Stdout: stdout,
+		Stderr: stderr,
+		Config: cfg,
+	}
+
+	err = cmd.Run(ctx, app, fs.Args()[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%s %s: %v\n", name, cmd.Name, err)
+		return 1
+	}
+
+	return 0
+}
+
+func lookup(name string) (Command, bool) {
+	for _, cmd := range Commands() {
+		if cmd.Name == name {
+			return cmd, true
+		}
+	}
+	return Command{}, false
+}
+
+func usage(w io.Writer, name string, fs *flag.FlagSet) {
+	fmt.Fprintf(w, "Usage: %s [flags] <command> [command flags]\n\n", name)
+	fmt.Fprintf(w, "Commands:\n")
+	for _, cmd := range Commands() {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.Name, cmd.Summary)
+	}
+	fmt.Fprintf(w, "  %-10s %s\n\n", "help", "Print this message")
+	fmt.Fprintf(w, "Flags:\n")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+// newFlagSet returns a flag set for a subcommand that writes its errors and
+// usage to the app's stderr.
+func newFlagSet(app *App, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(app.Name+" "+name, flag.ContinueOnError)
+	fs.SetOutput(app.Stderr)
+	return fs
+}
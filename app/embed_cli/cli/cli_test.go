@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		title      string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			title:      "No command",
+			args:       []string{"app"},
+			wantCode:   2,
+			wantStderr: "Usage: app",
+		},
+		{
+			title:      "Unknown command",
+			args:       []string{"app", "nope"},
+			wantCode:   2,
+			wantStderr: "unknown command 'nope'",
+		},
+		{
+			title:      "Help",
+			args:       []string{"app", "help"},
+			wantCode:   0,
+			wantStdout: "Commands:",
+		},
+		{
+			title:      "Version",
+			args:       []string{"app", "version"},
+			wantCode:   0,
+			wantStdout: "app ",
+		},
+		{
+			title:      "Hello with defaults",
+			args:       []string{"app", "hello"},
+			wantCode:   0,
+			wantStdout: "Hello, world!",
+		},
+		{
+			title:      "Hello with flags",
+			args:       []string{"app", "hello", "-name", "gopher", "-shout"},
+			wantCode:   0,
+			wantStdout: "HELLO, GOPHER!",
+		},
+		{
+			title:      "Hello with unexpected arguments",
+			args:       []string{"app", "hello", "extra"},
+			wantCode:   1,
+			wantStderr: "unexpected arguments: extra",
+		},
+		{
+			title:      "Missing config file",
+			args:       []string{"app", "-config", "does-not-exist.json", "hello"},
+			wantCode:   1,
+			wantStderr: "does-not-exist.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := Run(context.Background(), tt.args, &stdout, &stderr)
+
+			if code != tt.wantCode {
+				t.Errorf("code = %d, want = %d (stderr: %s)", code, tt.wantCode, stderr.String())
+			}
+
+			if !strings.Contains(stdout.String(), tt.wantStdout) {
+				t.Errorf("stdout = %q, want to contain %q", stdout.String(), tt.wantStdout)
+			}
+
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want to contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
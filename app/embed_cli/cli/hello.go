@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+func runHello(ctx context.Context, app *App, args []string) error {
+	fs := newFlagSet(app, "hello")
+	name := fs.String("name", app.Config.Name, "who to greet")
+	shout := fs.Bool("shout", false, "print the greeting in upper case")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	greeting := fmt.Sprintf("%s, %s!", app.Config.Greeting, *name)
+	if *shout {
+		greeting = strings.ToUpper(greeting)
+	}
+
+	_, err := fmt.Fprintln(app.Stdout, greeting)
+	return err
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Version is the application version. Set it at build time with:
+//
+//	go build -ldflags "-X $(go list -m)/cli.Version=v1.0.0"
+var Version = ""
+
+func runVersion(ctx context.Context, app *App, args []string) error {
+	fs := newFlagSet(app, "version")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(app.Stdout, "%s %s %s\n", app.Name, version(), runtime.Version())
+	return err
+}
+
+func version() string {
+	if Version != "" {
+		return Version
+	}
+
+	// Fall back to the module version when installed with 'go install'.
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+
+	return "(devel)"
+}
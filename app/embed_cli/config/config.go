@@ -0,0 +1,50 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Config is the application's configuration. Values are read from an optional
+// JSON file and then overridden by environment variables.
+type Config struct {
+	Greeting string `json:"greeting"`
+	Name     string `json:"name"`
+}
+
+// Default returns the configuration used when no file or environment
+// variables are provided.
+func Default() Config {
+	return Config{
+		Greeting: "Hello",
+		Name:     "world",
+	}
+}
+
+// Load returns the default configuration overlaid with the JSON file at path,
+// if path is not empty, and then with the APP_* environment variables.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("config: %w", err)
+		}
+
+		if err := json.Unmarshal(b, &cfg); err != nil {
+			return Config{}, fmt.Errorf("config: %s: %w", path, err)
+		}
+	}
+
+	if v, ok := os.LookupEnv("APP_GREETING"); ok {
+		cfg.Greeting = v
+	}
+
+	if v, ok := os.LookupEnv("APP_NAME"); ok {
+		cfg.Name = v
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		title   string
+		file    string
+		env     map[string]string
+		want    Config
+		wantErr bool
+	}{
+		{
+			title: "Defaults",
+			want:  Default(),
+		},
+		{
+			title: "File overrides defaults",
+			file:  `{"greeting": "Hi"}`,
+			want:  Config{Greeting: "Hi", Name: "world"},
+		},
+		{
+			title: "Environment overrides file",
+			file:  `{"greeting": "Hi", "name": "file"}`,
+			env:   map[string]string{"APP_NAME": "env"},
+			want:  Config{Greeting: "Hi", Name: "env"},
+		},
+		{
+			title:   "Invalid JSON",
+			file:    `{`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			path := ""
+			if tt.file != "" {
+				path = filepath.Join(t.TempDir(), "config.json")
+				err := os.WriteFile(path, []byte(tt.file), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got = %v, want = %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("got = %+v, want = %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package fsys
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"io"
+	"io/fs"
+	"path"
+	"time"
+)
+
+// Archive is an in-memory FS whose directories and files are written to an
+// archive by Close, in lexical order and with their permissions.
+type Archive struct {
+	*Mem
+	// Modification time of every entry, when Close is called by default. A
+	// fixed time makes the archive reproducible.
+	ModTime time.Time
+
+	w      io.Writer
+	prefix string
+	write  func(a *Archive, t time.Time) error
+}
+
+// NewTar returns an FS that Close writes to w as a tar archive. Every entry
+// is named under the directory prefix, e.g. 'my-app/go/go.mod', or at the
+// archive's root when prefix is empty.
+func NewTar(w io.Writer, prefix string) *Archive {
+	return &Archive{Mem: NewMem(), w: w, prefix: prefix, write: writeTar}
+}
+
+// NewZip returns an FS that Close writes to w as a zip archive, with entries
+// named like NewTar's.
+func NewZip(w io.Writer, prefix string) *Archive {
+	return &Archive{Mem: NewMem(), w: w, prefix: prefix, write: writeZip}
+}
+
+// Close writes the archive. It doesn't close the underlying writer.
+func (a *Archive) Close() error {
+	t := a.ModTime
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return a.write(a, t)
+}
+
+// walk calls fn with the archive name and info of each entry.
+func (a *Archive) walk(fn func(name string, info fs.FileInfo, b []byte) error) error {
+	return fs.WalkDir(a.Mem, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		entry := path.Join(a.prefix, name)
+		if entry == "." {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return fn(entry+"/", info, nil)
+		}
+
+		b, err := fs.ReadFile(a.Mem, name)
+		if err != nil {
+			return err
+		}
+		return fn(entry, info, b)
+	})
+}
+
+func writeTar(a *Archive, t time.Time) error {
+	tw := tar.NewWriter(a.w)
+
+	err := a.walk(func(name string, info fs.FileInfo, b []byte) error {
+		hdr := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     int64(info.Mode().Perm()),
+			Size:     int64(len(b)),
+			ModTime:  t,
+		}
+		if info.IsDir() {
+			hdr.Typeflag = tar.TypeDir
+		}
+
+		err := tw.WriteHeader(hdr)
+		if err != nil {
+			return err
+		}
+
+		_, err = tw.Write(b)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+func writeZip(a *Archive, t time.Time) error {
+	zw := zip.NewWriter(a.w)
+
+	err := a.walk(func(name string, info fs.FileInfo, b []byte) error {
+		hdr := &zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: t,
+		}
+		if info.IsDir() {
+			hdr.Method = zip.Store
+		}
+		hdr.SetMode(info.Mode())
+
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(b)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
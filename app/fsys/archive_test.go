@@ -0,0 +1,145 @@
+package fsys
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFiles fills the archive a with a small app.
+func writeFiles(t *testing.T, a *Archive) {
+	t.Helper()
+
+	for _, err := range []error{
+		a.MkdirAll("go/cmd", DirPerm),
+		a.WriteFile("go/cmd/main.go", []byte("package main\n"), FilePerm),
+		a.WriteFile(".env", []byte("SECRET=1\n"), SecretPerm),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// archived is an entry read back from an archive.
+type archived struct {
+	name string
+	mode fs.FileMode
+	data string
+}
+
+func TestTar(t *testing.T) {
+	var buf bytes.Buffer
+	a := NewTar(&buf, "my-app")
+	a.ModTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	writeFiles(t, a)
+
+	err := a.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []archived
+	tr := tar.NewReader(bytes.NewReader(buf.Bytes()))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !hdr.ModTime.Equal(a.ModTime) {
+			t.Errorf("%s: modified = %v, want = %v", hdr.Name, hdr.ModTime, a.ModTime)
+		}
+		got = append(got, archived{hdr.Name, fs.FileMode(hdr.Mode), string(b)})
+	}
+
+	assertArchived(t, got)
+
+	// The same files and time make the same archive.
+	var again bytes.Buffer
+	b := NewTar(&again, "my-app")
+	b.ModTime = a.ModTime
+	writeFiles(t, b)
+
+	err = b.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Error("archives of the same files differ")
+	}
+}
+
+func TestZip(t *testing.T) {
+	var buf bytes.Buffer
+	a := NewZip(&buf, "my-app")
+	writeFiles(t, a)
+
+	err := a.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []archived
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got = append(got, archived{f.Name, f.Mode().Perm(), string(b)})
+	}
+
+	assertArchived(t, got)
+}
+
+func assertArchived(t *testing.T, got []archived) {
+	t.Helper()
+
+	want := []archived{
+		{"my-app/", DirPerm, ""},
+		{"my-app/.env", SecretPerm, "SECRET=1\n"},
+		{"my-app/go/", DirPerm, ""},
+		{"my-app/go/cmd/", DirPerm, ""},
+		{"my-app/go/cmd/main.go", FilePerm, "package main\n"},
+	}
+
+	if len(got) != len(want) {
+		var names []string
+		for _, e := range got {
+			names = append(names, e.name)
+		}
+		t.Fatalf("entries = %s, want %d", strings.Join(names, " "), len(want))
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want = %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -1,70 +1,124 @@
 package fsys
 
 import (
-	"io"
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+
+	"create-go-app.dev/tmpl"
 )
 
 var EmbedPath string
 
-type fileService interface {
-	Create(name string) (*os.File, error)
-	WriteFile(name string, data []byte, perm os.FileMode) error
-	ReadAll(r io.Reader) ([]byte, error)
-	Mkdir(name string, perm os.FileMode) error
-}
+// Permissions of generated directories and files, before the umask is
+// applied.
+const (
+	DirPerm    = os.FileMode(0755)
+	FilePerm   = os.FileMode(0644)
+	ExecPerm   = os.FileMode(0755)
+	SecretPerm = os.FileMode(0600)
+)
 
-type FileReaderCloser interface {
-	Read(p []byte) (n int, err error)
-	Close() error
+// Mode returns the permissions of the generated file at path with contents
+// b: SecretPerm for files holding secrets, e.g. '.env', ExecPerm for scripts,
+// i.e. '.sh' files and files starting with a '#!' line, and FilePerm
+// otherwise. path may be an embedded template or its destination.
+func Mode(path string, b []byte) os.FileMode {
+	name := strings.TrimSuffix(filepath.Base(filepath.FromSlash(path)), tmpl.Suffix)
+
+	switch {
+	case isSecret(name):
+		return SecretPerm
+	case filepath.Ext(name) == ".sh", bytes.HasPrefix(b, []byte("#!")):
+		return ExecPerm
+	}
+	return FilePerm
 }
 
-type opener interface {
-	Open(name string) (FileReaderCloser, error)
+// Secret reports whether the generated file at path holds secrets, the files
+// Mode makes private. path may be an embedded template or its destination.
+func Secret(path string) bool {
+	return isSecret(strings.TrimSuffix(filepath.Base(filepath.FromSlash(path)), tmpl.Suffix))
 }
 
-func Output(name string, path string, isDir bool, o opener, fs fileService) error {
-	// Remove the 'embed' string from the path.
-	r := strings.Replace(path, EmbedPath, "", -1)
+// isSecret reports whether the file name holds secrets. Examples of
+// environment files, e.g. '.env.example', are meant to be shared.
+func isSecret(name string) bool {
+	switch filepath.Ext(name) {
+	case ".pem", ".key":
+		return true
+	}
 
-	// Join the new app's directory name to the new string.
-	dst := filepath.Join(name, strings.TrimPrefix(r, name))
+	if name != ".env" && !strings.HasPrefix(name, ".env.") {
+		return false
+	}
+	for _, suffix := range []string{".example", ".sample", ".template"} {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// Output creates the directory or file of the embedded path in src in the
+// app's FS dst. Files ending in tmpl.Suffix are rendered with data and
+// written without the suffix. Permissions are set by DirPerm and Mode.
+func Output(dst FS, path string, isDir bool, src fs.FS, data tmpl.Data) error {
+	name := Name(path)
 
-	// Create directories if they don't exist.
 	if isDir {
-		err := fs.Mkdir(dst, os.FileMode(0777))
-		if os.IsExist(err) {
-			return nil
-		} else {
-			return err
-		}
+		return dst.MkdirAll(name, DirPerm)
 	}
 
-	f, err := o.Open(path)
+	b, err := fs.ReadFile(src, path)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
-	b, err := fs.ReadAll(f)
+	b, err = Contents(path, b, data)
 	if err != nil {
 		return err
 	}
 
-	dstFile, err := fs.Create(dst)
-	if err != nil {
-		return err
+	return dst.WriteFile(name, b, Mode(path, b))
+}
+
+// Name returns the slash separated name the embedded path is written to in
+// the app's FS, e.g. 'go/go.mod' for 'embed/go/go.mod.tmpl' and '.' for
+// EmbedPath itself.
+func Name(path string) string {
+	rel := path
+	if EmbedPath != "" && (path == EmbedPath || strings.HasPrefix(path, EmbedPath+"/")) {
+		rel = strings.TrimPrefix(path[len(EmbedPath):], "/")
 	}
-	defer dstFile.Close()
 
-	err = fs.WriteFile(dst, b, os.FileMode(0777))
-	if err != nil {
-		return err
+	if rel == "" {
+		return "."
 	}
+	return strings.TrimSuffix(rel, tmpl.Suffix)
+}
 
-	return nil
+// Destination returns where the embedded path is written in the app's
+// directory dir, which may be relative, nested or absolute. A template's
+// suffix is removed.
+func Destination(dir string, path string) string {
+	return filepath.Join(dir, filepath.FromSlash(Name(path)))
+}
+
+// Contents returns what is written for the embedded file at path with
+// contents b. Templates are rendered with data.
+func Contents(path string, b []byte, data tmpl.Data) ([]byte, error) {
+	if !strings.HasSuffix(path, tmpl.Suffix) {
+		return b, nil
+	}
+	return tmpl.Render(path, b, data)
 }
 
 type FileDescriptor interface {
@@ -72,13 +126,9 @@ type FileDescriptor interface {
 	IsDir() bool
 }
 
-type FileReaderWriter interface {
-	ReadFile(name string) ([]byte, error)
-	WriteFile(name string, data []byte, perm os.FileMode) error
-}
-
-// Change go import paths in all files.
-func ReplaceImports(pattern string, path string, old string, new string, fd FileDescriptor, frw FileReaderWriter) error {
+// Change go import paths in all files matching pattern. path is the file's
+// name in fsys.
+func ReplaceImports(pattern string, path string, old string, new string, fd FileDescriptor, fsys FS) error {
 	if fd.IsDir() {
 		return nil
 	}
@@ -90,14 +140,17 @@ func ReplaceImports(pattern string, path string, old string, new string, fd File
 	}
 
 	if matched {
-		read, err := frw.ReadFile(path)
+		read, err := fs.ReadFile(fsys, path)
 		if err != nil {
 			return err
 		}
 
-		newContents := strings.Replace(string(read), old, new, -1)
+		newContents, err := RewriteImports(path, read, old, new)
+		if err != nil {
+			return err
+		}
 
-		err = frw.WriteFile(path, []byte(newContents), os.FileMode(0777))
+		err = fsys.WriteFile(path, newContents, Mode(path, newContents))
 
 		if err != nil {
 			return err
@@ -106,3 +159,60 @@ func ReplaceImports(pattern string, path string, old string, new string, fd File
 
 	return nil
 }
+
+// RewriteImports parses src as a Go file and changes every import path that
+// equals old, or is nested under it, to the same path under new. Aliases are
+// kept. String literals and comments are not touched. When an import changed
+// the file is re-printed with gofmt formatting, otherwise src is returned.
+func RewriteImports(filename string, src []byte, old string, new string) ([]byte, error) {
+	b, _, err := ImportChanges(filename, src, old, new)
+	return b, err
+}
+
+// ImportChange is an import path changed by RewriteImports.
+type ImportChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// ImportChanges is RewriteImports that also returns the changed imports.
+func ImportChanges(filename string, src []byte, old string, new string) ([]byte, []ImportChange, error) {
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var changes []ImportChange
+
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if path != old && !strings.HasPrefix(path, old+"/") {
+			continue
+		}
+
+		newPath := new + strings.TrimPrefix(path, old)
+		spec.Path.Value = strconv.Quote(newPath)
+		changes = append(changes, ImportChange{Old: path, New: newPath})
+	}
+
+	if len(changes) == 0 {
+		return src, nil, nil
+	}
+
+	// The new paths may sort differently within their import block.
+	ast.SortImports(fset, f)
+
+	var buf bytes.Buffer
+	err = format.Node(&buf, fset, f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return buf.Bytes(), changes, nil
+}
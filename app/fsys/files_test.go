@@ -3,132 +3,107 @@ package fsys
 import (
 	"embed"
 	"errors"
-	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
+
+	"create-go-app.dev/tmpl"
 )
 
-type MockFileOps struct {
-	ReadAllErr   error
-	CreateErr    error
+// MockFS is an in-memory FS whose methods fail with the set errors.
+type MockFS struct {
+	*Mem
+	OpenErr      error
 	WriteFileErr error
 	MkdirErr     error
 }
 
-func (m MockFileOps) Create(name string) (*os.File, error) {
-	if m.CreateErr != nil {
-		return nil, m.CreateErr
+func (m MockFS) Open(name string) (fs.File, error) {
+	if m.OpenErr != nil {
+		return nil, m.OpenErr
 	}
-	return os.Create(name)
+	return m.Mem.Open(name)
 }
 
-func (m MockFileOps) WriteFile(name string, data []byte, perm os.FileMode) error {
+func (m MockFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
 	if m.WriteFileErr != nil {
 		return m.WriteFileErr
 	}
-	return os.WriteFile(name, data, perm)
+	return m.Mem.WriteFile(name, data, perm)
 }
 
-func (m MockFileOps) ReadAll(r io.Reader) ([]byte, error) {
-	if m.ReadAllErr != nil {
-		return nil, m.ReadAllErr
-	}
-	return io.ReadAll(r)
-}
-
-func (m MockFileOps) Mkdir(name string, perm os.FileMode) error {
+func (m MockFS) MkdirAll(name string, perm fs.FileMode) error {
 	if m.MkdirErr != nil {
 		return m.MkdirErr
 	}
-	return os.Mkdir(name, perm)
-}
-
-type mockFSWrapper struct {
-	fs embed.FS
-}
-
-func (w mockFSWrapper) Open(name string) (FileReaderCloser, error) {
-	file, err := w.fs.Open(name)
-	if err != nil {
-		return nil, err
-	}
-
-	return file, nil
+	return m.Mem.MkdirAll(name, perm)
 }
 
 //go:embed all:_embed_test_
 var mockEmbed embed.FS
 
+// setEmbedPath roots the embedded paths of a test at _embed_test_.
+func setEmbedPath(t *testing.T) {
+	EmbedPath = "_embed_test_"
+	t.Cleanup(func() {
+		EmbedPath = ""
+	})
+}
+
 func TestWriteEmit(t *testing.T) {
-	mockEmbedded := mockFSWrapper{fs: mockEmbed}
+	setEmbedPath(t)
 
 	tests := []struct {
-		appName string
 		path    string
 		title   string
-		fileOps fileService
+		dst     MockFS
 		wantErr bool
 		isDir   bool
 	}{
 		{
 			title:   "Open error",
-			appName: "",
 			path:    "",
-			fileOps: MockFileOps{},
-			wantErr: true,
-		},
-		{
-			title:   "ReadAll error",
-			appName: "_embed_test_",
-			path:    "_embed_test_",
-			fileOps: MockFileOps{
-				ReadAllErr: errors.New("mock ReadAll error"),
-			},
+			dst:     MockFS{Mem: NewMem()},
 			wantErr: true,
 		},
 		{
-			title:   "Create error",
-			appName: "_embed_test_",
-			path:    "_embed_test_/root.txt",
-			fileOps: MockFileOps{
-				CreateErr: errors.New("mock Create error"),
-			},
+			title:   "Missing directory",
+			path:    "_embed_test_/dir/nested.txt",
+			dst:     MockFS{Mem: NewMem()},
 			wantErr: true,
 		},
 		{
-			title:   "WriteFile error",
-			appName: "_embed_test_",
-			path:    "_embed_test_/root.txt",
-			fileOps: MockFileOps{
+			title: "WriteFile error",
+			path:  "_embed_test_/root.txt",
+			dst: MockFS{
+				Mem:          NewMem(),
 				WriteFileErr: errors.New("mock WriteFile error"),
 			},
 			wantErr: true,
 		},
 		{
-			title:   "Mkdir error",
-			appName: "_embed_test_",
-			path:    "_embed_test_",
-			fileOps: MockFileOps{
+			title: "Mkdir error",
+			path:  "_embed_test_/dir",
+			dst: MockFS{
+				Mem:      NewMem(),
 				MkdirErr: errors.New("mock Mkdir error"),
 			},
 			wantErr: true,
 			isDir:   true,
 		},
 		{
-			title:   "isExists error",
-			appName: "_embed_test_",
+			title:   "Root directory",
 			path:    "_embed_test_",
-			fileOps: MockFileOps{
-				MkdirErr: os.ErrExist,
-			},
+			dst:     MockFS{Mem: NewMem()},
 			wantErr: false,
 			isDir:   true,
 		},
 		{
 			title:   "Success",
-			appName: "_embed_test_",
 			path:    "_embed_test_/root.txt",
-			fileOps: MockFileOps{},
+			dst:     MockFS{Mem: NewMem()},
 			wantErr: false,
 			isDir:   false,
 		},
@@ -136,7 +111,7 @@ func TestWriteEmit(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.title, func(t *testing.T) {
-			err := Output(tt.appName, tt.path, tt.isDir, mockEmbedded, tt.fileOps)
+			err := Output(tt.dst, tt.path, tt.isDir, mockEmbed, tmpl.Data{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("got = %v, want = %v", err, tt.wantErr)
 			}
@@ -144,24 +119,176 @@ func TestWriteEmit(t *testing.T) {
 	}
 }
 
-type MockChangeImportsOpts struct {
-	ReadFileErr  error
-	WriteFileErr error
-	ReadFileData string
+func TestOutputTemplate(t *testing.T) {
+	setEmbedPath(t)
+	data := tmpl.Data{AppName: "my-app"}
+
+	dst := NewMem()
+	err := Output(dst, "_embed_test_/dir", true, mockEmbed, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Output(dst, "_embed_test_/dir/greeting.txt.tmpl", false, mockEmbed, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := fs.ReadFile(dst, "dir/greeting.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "Hello my-app\n" {
+		t.Errorf("expected data = %q, got = %q", "Hello my-app\n", string(b))
+	}
+
+	err = Output(dst, "_embed_test_/dir/invalid.txt.tmpl", false, mockEmbed, data)
+	if err == nil {
+		t.Errorf("got = %v, want = true", err)
+	}
 }
 
-func (m MockChangeImportsOpts) ReadFile(name string) ([]byte, error) {
-	if m.ReadFileErr != nil {
-		return nil, m.ReadFileErr
+func TestDestination(t *testing.T) {
+	EmbedPath = "embed"
+	t.Cleanup(func() {
+		EmbedPath = ""
+	})
+
+	tests := []struct {
+		title string
+		dir   string
+		path  string
+		want  string
+	}{
+		{title: "Root", dir: "my-app", path: "embed", want: "my-app"},
+		{title: "File", dir: "my-app", path: "embed/go/go.mod.tmpl", want: filepath.Join("my-app", "go", "go.mod")},
+		{title: "Current directory", dir: ".", path: "embed/.env.tmpl", want: ".env"},
+		{title: "Nested", dir: filepath.Join("services", "api"), path: "embed/go/cmd/main.go", want: filepath.Join("services", "api", "go", "cmd", "main.go")},
+		{title: "Absolute", dir: filepath.Join(os.TempDir(), "my-app"), path: "embed/README.md", want: filepath.Join(os.TempDir(), "my-app", "README.md")},
+		{title: "Name containing the embed path", dir: "embed", path: "embed/go/embed/embed.go", want: filepath.Join("embed", "go", "embed", "embed.go")},
+		{title: "Similar prefix", dir: "my-app", path: "embedded/x.txt", want: filepath.Join("my-app", "embedded", "x.txt")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got := Destination(tt.dir, tt.path)
+			if got != tt.want {
+				t.Errorf("got = %q, want = %q", got, tt.want)
+			}
+		})
 	}
-	return os.ReadFile(name)
 }
 
-func (m MockChangeImportsOpts) WriteFile(name string, data []byte, perm os.FileMode) error {
-	if m.WriteFileErr != nil {
-		return m.WriteFileErr
+func TestMode(t *testing.T) {
+	tests := []struct {
+		path string
+		b    string
+		want os.FileMode
+	}{
+		{path: "embed/go/cmd/main.go", b: "package main\n", want: FilePerm},
+		{path: "embed/postgres/migrations/1_init.up.sql", want: FilePerm},
+		{path: "embed/.env.tmpl", want: SecretPerm},
+		{path: "my-app/.env", want: SecretPerm},
+		{path: ".env.local", want: SecretPerm},
+		{path: ".env.example", want: FilePerm},
+		{path: ".env.rej", want: SecretPerm},
+		{path: "certs/server.key", want: SecretPerm},
+		{path: "certs/server.pem", want: SecretPerm},
+		{path: "scripts/migrate.sh", want: ExecPerm},
+		{path: "scripts/migrate.sh.tmpl", want: ExecPerm},
+		{path: "bin/wait-for", b: "#!/bin/sh\n", want: ExecPerm},
+		{path: "README.md", b: "# title\n", want: FilePerm},
+		{path: "environment.go", want: FilePerm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got := Mode(tt.path, []byte(tt.b))
+			if got != tt.want {
+				t.Errorf("got = %04o, want = %04o", got, tt.want)
+			}
+		})
 	}
-	return os.WriteFile(name, data, perm)
+}
+
+// umask returns the process's umask, read from a file created with every
+// permission.
+func umask(t *testing.T) os.FileMode {
+	f, err := os.OpenFile(filepath.Join(t.TempDir(), "umask"), os.O_CREATE|os.O_WRONLY, 0777)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return 0777 &^ info.Mode().Perm()
+}
+
+func TestOutputMode(t *testing.T) {
+	setEmbedPath(t)
+
+	tests := []struct {
+		path  string
+		isDir bool
+		dst   string
+		want  os.FileMode
+	}{
+		{path: "_embed_test_/dir", isDir: true, dst: "dir", want: DirPerm},
+		{path: "_embed_test_/root.txt", dst: "root.txt", want: FilePerm},
+		{path: "_embed_test_/dir/greeting.txt.tmpl", dst: "dir/greeting.txt", want: FilePerm},
+		{path: "_embed_test_/.env", dst: ".env", want: SecretPerm},
+		{path: "_embed_test_/dir/run", dst: "dir/run", want: ExecPerm},
+	}
+
+	t.Run("Memory", func(t *testing.T) {
+		dst := NewMem()
+
+		for _, tt := range tests {
+			err := Output(dst, tt.path, tt.isDir, mockEmbed, tmpl.Data{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := fs.Stat(dst, tt.dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if info.Mode().Perm() != tt.want {
+				t.Errorf("%s: got = %04o, want = %04o", tt.dst, info.Mode().Perm(), tt.want)
+			}
+		}
+	})
+
+	t.Run("OS", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Windows has no Unix permissions")
+		}
+
+		mask := umask(t)
+		dir := t.TempDir()
+
+		for _, tt := range tests {
+			err := Output(OS(dir), tt.path, tt.isDir, mockEmbed, tmpl.Data{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(tt.dst)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := tt.want &^ mask
+			if info.Mode().Perm() != want {
+				t.Errorf("%s: got = %04o, want = %04o", tt.dst, info.Mode().Perm(), want)
+			}
+		}
+	})
 }
 
 type MockFileDescriptor struct {
@@ -178,17 +305,17 @@ func (m MockFileDescriptor) Name() string {
 }
 
 func TestChangeImports(t *testing.T) {
+	src := "package main\nimport \"old/import/path\""
+
 	tests := []struct {
 		title        string
 		path         string
 		fd           FileDescriptor
 		prev         string
 		new          string
-		ops          MockChangeImportsOpts
+		fsys         MockFS
 		wantErr      bool
 		expectedData string
-		setup        func()
-		teardown     func()
 	}{
 		{
 			title:   "FileDescriptor is a directory",
@@ -196,7 +323,7 @@ func TestChangeImports(t *testing.T) {
 			fd:      MockFileDescriptor{isDir: true, name: "dir"},
 			prev:    "old/import/path",
 			new:     "new/import/path",
-			ops:     MockChangeImportsOpts{},
+			fsys:    MockFS{Mem: NewMem()},
 			wantErr: false,
 		},
 		{
@@ -205,7 +332,7 @@ func TestChangeImports(t *testing.T) {
 			fd:      MockFileDescriptor{isDir: false, name: "file.txt"},
 			prev:    "old/import/path",
 			new:     "new/import/path",
-			ops:     MockChangeImportsOpts{},
+			fsys:    MockFS{Mem: NewMem()},
 			wantErr: false,
 		},
 		{
@@ -214,36 +341,17 @@ func TestChangeImports(t *testing.T) {
 			fd:      MockFileDescriptor{isDir: false, name: "file.go"},
 			prev:    "old/import/path",
 			new:     "new/import/path",
-			ops:     MockChangeImportsOpts{ReadFileErr: errors.New("mock ReadFile error")},
+			fsys:    MockFS{Mem: NewMem(), OpenErr: errors.New("mock Open error")},
 			wantErr: true,
 		},
 		{
-			title: "WriteFile error",
-			path:  "file.go",
-			fd:    MockFileDescriptor{isDir: false, name: "file.go"},
-			prev:  "old/import/path",
-			new:   "new/import/path",
-			ops: MockChangeImportsOpts{
-				ReadFileData: "package main\nimport \"old/import/path\"",
-				WriteFileErr: errors.New("mock WriteFile error"),
-			},
+			title:   "WriteFile error",
+			path:    "file.go",
+			fd:      MockFileDescriptor{isDir: false, name: "file.go"},
+			prev:    "old/import/path",
+			new:     "new/import/path",
+			fsys:    MockFS{Mem: NewMem(), WriteFileErr: errors.New("mock WriteFile error")},
 			wantErr: true,
-			setup: func() {
-				_, err := os.Create("file.go")
-				if err != nil {
-					t.Fatal(err)
-				}
-				err = os.WriteFile("file.go", []byte("package main\nimport \"old/import/path\""), os.FileMode(0777))
-				if err != nil {
-					t.Fatal(err)
-				}
-			},
-			teardown: func() {
-				err := os.Remove("file.go")
-				if err != nil {
-					t.Fatal(err)
-				}
-			},
 		},
 		{
 			title:        "Successful import replacement",
@@ -251,49 +359,93 @@ func TestChangeImports(t *testing.T) {
 			fd:           MockFileDescriptor{isDir: false, name: "file.go"},
 			prev:         "old/import/path",
 			new:          "new/import/path",
-			ops:          MockChangeImportsOpts{},
+			fsys:         MockFS{Mem: NewMem()},
 			wantErr:      false,
-			expectedData: "package main\nimport \"new/import/path\"",
-			setup: func() {
-				_, err := os.Create("file.go")
-				if err != nil {
-					t.Fatal(err)
-				}
-				err = os.WriteFile("file.go", []byte("package main\nimport \"old/import/path\""), os.FileMode(0777))
-				if err != nil {
-					t.Fatal(err)
-				}
-			},
-			teardown: func() {
-				err := os.Remove("file.go")
-				if err != nil {
-					t.Fatal(err)
-				}
-			},
+			expectedData: "package main\n\nimport \"new/import/path\"\n",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.title, func(t *testing.T) {
-			if tt.setup != nil {
-				tt.setup()
+			err := tt.fsys.Mem.WriteFile("file.go", []byte(src), FilePerm)
+			if err != nil {
+				t.Fatal(err)
 			}
 
-			err := ReplaceImports("*.go", tt.path, tt.prev, tt.new, tt.fd, tt.ops)
+			err = ReplaceImports("*.go", tt.path, tt.prev, tt.new, tt.fd, tt.fsys)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("got = %v, want = %v", err, tt.wantErr)
 			}
 
 			if tt.expectedData != "" && err == nil {
-				readData, _ := tt.ops.ReadFile(tt.path)
+				readData, _ := fs.ReadFile(tt.fsys, tt.path)
 				if string(readData) != tt.expectedData {
 					t.Errorf("expected data = %v, got = %v", tt.expectedData, string(readData))
 				}
 			}
+		})
+	}
+}
+
+func TestRewriteImports(t *testing.T) {
+	tests := []struct {
+		title   string
+		src     string
+		want    string
+		wantErr bool
+	}{
+		{
+			title: "Exact path",
+			src:   "package main\n\nimport \"github.com/username/repo\"\n",
+			want:  "package main\n\nimport \"example.com/app\"\n",
+		},
+		{
+			title: "Nested path",
+			src:   "package main\n\nimport \"github.com/username/repo/http\"\n",
+			want:  "package main\n\nimport \"example.com/app/http\"\n",
+		},
+		{
+			title: "Alias is preserved",
+			src:   "package main\n\nimport thing \"github.com/username/repo\"\n",
+			want:  "package main\n\nimport thing \"example.com/app\"\n",
+		},
+		{
+			title: "Path that only shares the prefix",
+			src:   "package main\n\nimport \"github.com/username/repository\"\n",
+			want:  "package main\n\nimport \"github.com/username/repository\"\n",
+		},
+		{
+			title: "String literals and comments are untouched",
+			src:   "package main\n\nimport \"github.com/username/repo/http\"\n\n// github.com/username/repo\nvar s = \"github.com/username/repo\"\n",
+			want:  "package main\n\nimport \"example.com/app/http\"\n\n// github.com/username/repo\nvar s = \"github.com/username/repo\"\n",
+		},
+		{
+			title: "Imports are re-sorted",
+			src:   "package main\n\nimport (\n\t\"github.com/username/repo/http\"\n\t\"github.com/username/repo/cors\"\n)\n",
+			want:  "package main\n\nimport (\n\t\"example.com/app/cors\"\n\t\"example.com/app/http\"\n)\n",
+		},
+		{
+			title: "No matching imports",
+			src:   "package main\nimport \"fmt\"",
+			want:  "package main\nimport \"fmt\"",
+		},
+		{
+			title:   "Invalid Go",
+			src:     "package",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got, err := RewriteImports("file.go", []byte(tt.src), "github.com/username/repo", "example.com/app")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got = %v, want = %v", err, tt.wantErr)
+			}
 
-			if tt.teardown != nil {
-				tt.teardown()
+			if string(got) != tt.want {
+				t.Errorf("expected data = %q, got = %q", tt.want, string(got))
 			}
 		})
 	}
@@ -0,0 +1,176 @@
+package fsys
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing/fstest"
+	"time"
+)
+
+// FS is a file system generated files are written to. Names are slash
+// separated and unrooted, as in io/fs, and files are read through fs.FS.
+type FS interface {
+	fs.FS
+	// MkdirAll creates the directory name along with any missing parents.
+	MkdirAll(name string, perm fs.FileMode) error
+	// WriteFile writes data to the file name, creating it with perm if it
+	// doesn't exist. Existing files keep their permissions.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// osFS is the FS of a directory on disk.
+type osFS struct {
+	fs.FS
+	dir string
+}
+
+// OS returns the FS of the directory dir on disk. Permissions are applied
+// less the process's umask.
+func OS(dir string) FS {
+	return osFS{FS: os.DirFS(dir), dir: dir}
+}
+
+func (o osFS) MkdirAll(name string, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	return os.MkdirAll(filepath.Join(o.dir, filepath.FromSlash(name)), perm)
+}
+
+func (o osFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	return os.WriteFile(filepath.Join(o.dir, filepath.FromSlash(name)), data, perm)
+}
+
+// Mem is an FS held in memory. Like on disk, a file's directory must exist
+// before the file is written. It is safe for concurrent use.
+type Mem struct {
+	mu    sync.RWMutex
+	files fstest.MapFS
+}
+
+// NewMem returns an empty in-memory FS.
+func NewMem() *Mem {
+	return &Mem{files: fstest.MapFS{
+		".": {Mode: fs.ModeDir | DirPerm, ModTime: time.Now()},
+	}}
+}
+
+func (m *Mem) Open(name string) (fs.File, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.files.Open(name)
+}
+
+func (m *Mem) MkdirAll(name string, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for dir := name; dir != "."; dir = path.Dir(dir) {
+		f, ok := m.files[dir]
+		if ok && !f.Mode.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: dir, Err: errNotDir}
+		}
+		if !ok {
+			m.files[dir] = &fstest.MapFile{Mode: fs.ModeDir | perm.Perm(), ModTime: now}
+		}
+	}
+	return nil
+}
+
+func (m *Mem) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if dir := path.Dir(name); dir != "." {
+		f, ok := m.files[dir]
+		if !ok {
+			return &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		if !f.Mode.IsDir() {
+			return &fs.PathError{Op: "open", Path: name, Err: errNotDir}
+		}
+	}
+
+	mode := perm.Perm()
+	if f, ok := m.files[name]; ok {
+		if f.Mode.IsDir() {
+			return &fs.PathError{Op: "open", Path: name, Err: errIsDir}
+		}
+		mode = f.Mode
+	}
+
+	// Files are replaced rather than changed, so ones already opened keep
+	// reading the old contents.
+	m.files[name] = &fstest.MapFile{
+		Data:    append([]byte(nil), data...),
+		Mode:    mode,
+		ModTime: time.Now(),
+	}
+	return nil
+}
+
+// RemoveAll removes name and, if it is a directory, everything in it. A
+// missing name isn't an error.
+func (m *Mem) RemoveAll(name string) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "removeall", Path: name, Err: fs.ErrInvalid}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for p := range m.files {
+		if p == name || strings.HasPrefix(p, name+"/") {
+			delete(m.files, p)
+		}
+	}
+	return nil
+}
+
+var (
+	errNotDir = errors.New("not a directory")
+	errIsDir  = errors.New("is a directory")
+)
+
+// Copy writes every directory and file of src to dst with the same
+// permissions.
+func Copy(dst FS, src fs.FS) error {
+	return fs.WalkDir(src, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return dst.MkdirAll(name, info.Mode().Perm())
+		}
+
+		b, err := fs.ReadFile(src, name)
+		if err != nil {
+			return err
+		}
+		return dst.WriteFile(name, b, info.Mode().Perm())
+	})
+}
@@ -0,0 +1,167 @@
+package fsys
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMem(t *testing.T) {
+	m := NewMem()
+
+	err := m.MkdirAll("go/cmd", DirPerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.WriteFile("go/cmd/main.go", []byte("package main\n"), FilePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.WriteFile(".env", []byte("SECRET=1\n"), SecretPerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fstest.TestFS(m, "go/cmd/main.go", ".env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		title string
+		err   error
+		want  error
+	}{
+		{
+			title: "Missing directory",
+			err:   m.WriteFile("missing/file.txt", nil, FilePerm),
+			want:  fs.ErrNotExist,
+		},
+		{
+			title: "Invalid name",
+			err:   m.WriteFile("/abs.txt", nil, FilePerm),
+			want:  fs.ErrInvalid,
+		},
+		{
+			title: "Directory under a file",
+			err:   m.MkdirAll(".env/dir", DirPerm),
+			want:  errNotDir,
+		},
+		{
+			title: "File over a directory",
+			err:   m.WriteFile("go", nil, FilePerm),
+			want:  errIsDir,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.want) {
+				t.Errorf("got = %v, want = %v", tt.err, tt.want)
+			}
+		})
+	}
+
+	t.Run("Existing files keep their permissions", func(t *testing.T) {
+		err := m.WriteFile(".env", []byte("SECRET=2\n"), FilePerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := fs.Stat(m, ".env")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != SecretPerm {
+			t.Errorf("got = %04o, want = %04o", info.Mode().Perm(), SecretPerm)
+		}
+
+		b, err := fs.ReadFile(m, ".env")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "SECRET=2\n" {
+			t.Errorf("got = %q, want = %q", b, "SECRET=2\n")
+		}
+	})
+
+	t.Run("RemoveAll", func(t *testing.T) {
+		err := m.MkdirAll("gopher", DirPerm)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, name := range []string{"go", "missing"} {
+			err = m.RemoveAll(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// A directory sharing the removed one's prefix stays.
+		err = fstest.TestFS(m, ".env", "gopher")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fs.Stat(m, "go/cmd/main.go"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("got = %v, want = %v", err, fs.ErrNotExist)
+		}
+	})
+}
+
+func TestCopy(t *testing.T) {
+	src := NewMem()
+	for _, err := range []error{
+		src.MkdirAll("scripts", DirPerm),
+		src.WriteFile("scripts/run.sh", []byte("#!/bin/sh\n"), ExecPerm),
+		src.WriteFile(".env", []byte("SECRET=1\n"), SecretPerm),
+		src.MkdirAll("empty", DirPerm),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := t.TempDir()
+	err := Copy(OS(dir), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "scripts", "run.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "#!/bin/sh\n" {
+		t.Errorf("got = %q, want = %q", b, "#!/bin/sh\n")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "empty")); err != nil {
+		t.Error(err)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	mask := umask(t)
+	for name, want := range map[string]os.FileMode{
+		"scripts":        DirPerm,
+		"scripts/run.sh": ExecPerm,
+		".env":           SecretPerm,
+	} {
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want&^mask {
+			t.Errorf("%s: got = %04o, want = %04o", name, info.Mode().Perm(), want&^mask)
+		}
+	}
+}
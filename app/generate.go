@@ -0,0 +1,240 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"create-go-app.dev/fsys"
+	"create-go-app.dev/gotools"
+	"create-go-app.dev/manifest"
+	"create-go-app.dev/resource"
+
+	"github.com/fatih/color"
+)
+
+// generate runs 'create-go-app generate <kind> ...'.
+func generate(args []string) error {
+	if len(args) == 0 || args[0] != "resource" {
+		return fmt.Errorf("create-go-app: usage: create-go-app generate resource [flags] <Name> field:type ...")
+	}
+
+	return generateResource(args[1:])
+}
+
+// generateResource adds a resource to the http project in -dir. Every file is
+// rendered and patched in memory first so that nothing is written when any
+// step fails.
+func generateResource(args []string) error {
+	flags := flag.NewFlagSet("generate resource", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "root directory of the generated http project")
+	force := flags.Bool("force", false, "overwrite the resource's files if they already exist")
+
+	flags.Usage = func() {
+		fmt.Printf("  To add a 'BlogPost' resource to the project in the current directory run:\n")
+		fmt.Printf("  create-go-app generate resource BlogPost title:string body:text published_at:time\n")
+		fmt.Printf("  Field types: %s\n", strings.Join(resource.FieldTypes(), ", "))
+		flags.PrintDefaults()
+	}
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return fmt.Errorf("create-go-app: missing resource name")
+	}
+
+	goDir := filepath.Join(*dir, "go")
+
+	gomod, err := os.ReadFile(filepath.Join(goDir, "go.mod"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("create-go-app: %s not found, run 'generate resource' in the root of a generated http project", filepath.Join(goDir, "go.mod"))
+	}
+	if err != nil {
+		return err
+	}
+
+	module, err := gotools.ModulePath(gomod)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filepath.Join(goDir, "postgres")); err != nil {
+		return fmt.Errorf("create-go-app: resources are stored in Postgres, the project has no postgres component")
+	}
+
+	pkg, err := rootPackage(goDir)
+	if err != nil {
+		return err
+	}
+
+	r, err := resource.New(flags.Arg(0), flags.Args()[1:], module, pkg)
+	if err != nil {
+		return err
+	}
+
+	files, err := resource.Files(r, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for name := range files {
+		if _, err := os.Stat(filepath.Join(*dir, name)); err == nil && !*force {
+			return fmt.Errorf("create-go-app: %s already exists, pass -force to overwrite it", name)
+		}
+	}
+
+	// Wire the resource into the existing files. A missing marker means the
+	// user edited it away, they are told what to add by hand instead.
+	patches := map[string]func([]byte, resource.Resource) ([]byte, error){
+		"go/cmd/main.go": resource.PatchMain,
+		"swagger.yaml":   resource.PatchOpenAPI,
+	}
+
+	patched := map[string][]byte{}
+	var manual []string
+
+	// Whether each patched file differed from what the generator wrote,
+	// checked before it is overwritten.
+	modified := map[string]bool{}
+	m, err := manifest.Read(*dir)
+	if err != nil && !errors.Is(err, manifest.ErrNotFound) {
+		return err
+	}
+
+	for name, patch := range patches {
+		src, err := os.ReadFile(filepath.Join(*dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		b, err := patch(src, r)
+		if errors.Is(err, resource.ErrNoMarker) {
+			manual = append(manual, name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		patched[name] = b
+
+		if m != nil {
+			modified[name], err = m.Modified(*dir, name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(files) {
+		dst := filepath.Join(*dir, name)
+
+		err := os.MkdirAll(filepath.Dir(dst), fsys.DirPerm)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(dst, files[name], fsys.Mode(name, files[name]))
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(color.Output, "%s %s\n", color.GreenString("create"), name)
+	}
+
+	for _, name := range sortedKeys(patched) {
+		err := os.WriteFile(filepath.Join(*dir, name), patched[name], fsys.Mode(name, patched[name]))
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(color.Output, "%s %s\n", color.CyanString("update"), name)
+	}
+
+	for _, name := range manual {
+		fmt.Fprintf(color.Output, "%s %s has no create-go-app marker comments, wire up %s by hand\n", color.YellowString("skip"), name, r.Name.Pascal)
+	}
+
+	if m == nil {
+		return nil
+	}
+	return recordResource(*dir, m, r, files, patched, modified)
+}
+
+// recordResource adds the resource and the files written for it to the
+// manifest m of the project in dir. The checksums of patched
+// files are only updated when the user hadn't modified them, so they still
+// show as modified afterwards.
+func recordResource(dir string, m *manifest.Manifest, r resource.Resource, files map[string][]byte, patched map[string][]byte, modified map[string]bool) error {
+	for _, name := range sortedKeys(files) {
+		m.Add(manifest.File{
+			Path:   filepath.ToSlash(name),
+			Source: "generate resource " + r.Name.Pascal,
+			SHA256: manifest.Checksum(files[name]),
+		})
+	}
+
+	for _, name := range sortedKeys(patched) {
+		f, ok := m.Lookup(filepath.ToSlash(name))
+		if !ok || modified[name] {
+			continue
+		}
+		f.SHA256 = manifest.Checksum(patched[name])
+		m.Add(f)
+	}
+
+	if !slices.Contains(m.Options.Resources, r.Name.Pascal) {
+		m.Options.Resources = append(m.Options.Resources, r.Name.Pascal)
+	}
+	m.Generator.Version = generatorVersion()
+	m.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+
+	return m.Write(dir)
+}
+
+// rootPackage returns the package name of the Go files in dir, e.g. 'thing'.
+func rootPackage(dir string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", err
+	}
+
+	for _, m := range matches {
+		if strings.HasSuffix(m, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(token.NewFileSet(), m, nil, parser.PackageClauseOnly)
+		if err != nil {
+			return "", err
+		}
+
+		return f.Name.Name, nil
+	}
+
+	return "", fmt.Errorf("create-go-app: no Go files in %s", dir)
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
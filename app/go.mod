@@ -5,6 +5,8 @@ go 1.23.5
 require (
 	github.com/fatih/color v1.18.0
 	github.com/joho/godotenv v1.5.1
+	golang.org/x/term v0.23.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
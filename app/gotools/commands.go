@@ -1,61 +1,20 @@
 package gotools
 
-import (
-	"bufio"
-	"fmt"
-	"os"
-	"os/exec"
-	"strings"
-)
+import "context"
 
-func FormatCode() ([]byte, error) {
-	cmd := exec.Command("go", "fmt", "./...")
-	b, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, err
-	}
-	return b, nil
+// FormatCode runs 'go fmt ./...' in dir.
+func (r *Runner) FormatCode(ctx context.Context, dir string) ([]byte, error) {
+	return r.Run(ctx, dir, "go", "fmt", "./...")
 }
 
-func InitializeModule(module string) ([]byte, error) {
-	cmd := exec.Command("go", "mod", "init", module)
-	b, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, err
-	}
-	return b, nil
+// UpgradeDeps runs 'go get -u ./...' in dir.
+func (r *Runner) UpgradeDeps(ctx context.Context, dir string) error {
+	_, err := r.Run(ctx, dir, "go", "get", "-u", "./...")
+	return err
 }
 
-func EnterModuleName() (string, error) {
-	fmt.Print("Enter the name of the module: ")
-	reader := bufio.NewReader(os.Stdin)
-	input, err := reader.ReadString('\n')
-	if err != nil {
-		return "", err
-	}
-
-	input = strings.TrimSpace(input)
-	if len(input) == 0 {
-		return "", fmt.Errorf("create-go-app: please provide a module name")
-	}
-
-	return input, nil
-}
-
-func ChangeModuleName(name string) error {
-	cmd := exec.Command("go", "mod", "edit", "-module", name)
-	_, err := cmd.CombinedOutput()
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func GetAllDeps() error {
-	cmd := exec.Command("go", "get", "./...")
-	_, err := cmd.CombinedOutput()
-	if err != nil {
-		return err
-	}
-	return nil
+// Tidy runs 'go mod tidy' in dir.
+func (r *Runner) Tidy(ctx context.Context, dir string) error {
+	_, err := r.Run(ctx, dir, "go", "mod", "tidy")
+	return err
 }
@@ -0,0 +1,116 @@
+package gotools
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ModulePath returns the module path declared in the contents of a go.mod
+// file.
+func ModulePath(gomod []byte) (string, error) {
+	s := bufio.NewScanner(bytes.NewReader(gomod))
+	for s.Scan() {
+		line := s.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+
+		path := fields[1]
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			path = unquoted
+		}
+		return path, nil
+	}
+
+	return "", fmt.Errorf("create-go-app: no module directive in go.mod")
+}
+
+// Require is a requirement in a go.mod file.
+type Require struct {
+	Path     string
+	Version  string
+	Indirect bool
+}
+
+// Requirements returns the requirements in the contents of a go.mod file,
+// from both single line and block require directives.
+func Requirements(gomod []byte) ([]Require, error) {
+	var requires []Require
+	inBlock := false
+
+	s := bufio.NewScanner(bytes.NewReader(gomod))
+	for n := 1; s.Scan(); n++ {
+		line, comment, _ := strings.Cut(s.Text(), "//")
+		fields := strings.Fields(line)
+
+		switch {
+		case inBlock && len(fields) == 1 && fields[0] == ")":
+			inBlock = false
+			continue
+		case !inBlock && len(fields) == 2 && fields[0] == "require" && fields[1] == "(":
+			inBlock = true
+			continue
+		case !inBlock && len(fields) > 0 && fields[0] == "require":
+			fields = fields[1:]
+		case !inBlock:
+			continue
+		}
+
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("create-go-app: go.mod line %d: expected 'path version'", n)
+		}
+		path := fields[0]
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			path = unquoted
+		}
+		requires = append(requires, Require{
+			Path:     path,
+			Version:  fields[1],
+			Indirect: strings.TrimSpace(comment) == "indirect",
+		})
+	}
+
+	return requires, nil
+}
+
+// Move is a requirement whose version changed. Old is empty for an added
+// requirement and New for a removed one.
+type Move struct {
+	Path string
+	Old  string
+	New  string
+}
+
+// Moved returns the requirements whose version differs between before and
+// after, sorted by path.
+func Moved(before, after []Require) []Move {
+	versions := map[string]*Move{}
+	for _, r := range before {
+		versions[r.Path] = &Move{Path: r.Path, Old: r.Version}
+	}
+	for _, r := range after {
+		if m, ok := versions[r.Path]; ok {
+			m.New = r.Version
+			continue
+		}
+		versions[r.Path] = &Move{Path: r.Path, New: r.Version}
+	}
+
+	var moves []Move
+	for _, m := range versions {
+		if m.Old != m.New {
+			moves = append(moves, *m)
+		}
+	}
+	sort.Slice(moves, func(i, j int) bool { return moves[i].Path < moves[j].Path })
+	return moves
+}
@@ -0,0 +1,77 @@
+package gotools
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRequirements(t *testing.T) {
+	tests := []struct {
+		title   string
+		gomod   string
+		want    []Require
+		wantErr bool
+	}{
+		{
+			title: "Blocks and single lines",
+			gomod: `module example.com/app
+
+go 1.23.5
+
+require github.com/gorilla/mux v1.8.1
+
+require (
+	github.com/rs/cors v1.11.1 // a comment
+	"github.com/lib/pq" v1.10.9
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+)
+`,
+			want: []Require{
+				{Path: "github.com/gorilla/mux", Version: "v1.8.1"},
+				{Path: "github.com/rs/cors", Version: "v1.11.1"},
+				{Path: "github.com/lib/pq", Version: "v1.10.9"},
+				{Path: "github.com/cespare/xxhash/v2", Version: "v2.2.0", Indirect: true},
+			},
+		},
+		{title: "No requirements", gomod: "module example.com/app\n\ngo 1.23.5\n"},
+		{title: "Malformed requirement", gomod: "require (\n\tgithub.com/rs/cors\n)\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got, err := Requirements([]byte(tt.gomod))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoved(t *testing.T) {
+	before := []Require{
+		{Path: "github.com/rs/cors", Version: "v1.11.1"},
+		{Path: "github.com/lib/pq", Version: "v1.10.9"},
+		{Path: "github.com/old/dep", Version: "v1.0.0"},
+	}
+	after := []Require{
+		{Path: "github.com/rs/cors", Version: "v1.11.1"},
+		{Path: "github.com/lib/pq", Version: "v1.12.3"},
+		{Path: "github.com/new/dep", Version: "v0.1.0"},
+	}
+
+	got := Moved(before, after)
+	want := []Move{
+		{Path: "github.com/lib/pq", Old: "v1.10.9", New: "v1.12.3"},
+		{Path: "github.com/new/dep", New: "v0.1.0"},
+		{Path: "github.com/old/dep", Old: "v1.0.0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,165 @@
+package gotools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Sum is a module version listed in a go.sum file.
+type Sum struct {
+	Path    string
+	Version string
+	// GoModOnly is set when go.sum only has the hash of the module's go.mod,
+	// i.e. the module is part of the build graph but its code isn't needed.
+	GoModOnly bool
+}
+
+func (s Sum) String() string { return s.Path + "@" + s.Version }
+
+// ParseSum returns the module versions in the contents of a go.sum file,
+// sorted by path and version.
+func ParseSum(gosum []byte) ([]Sum, error) {
+	seen := map[string]int{}
+	var sums []Sum
+
+	s := bufio.NewScanner(bytes.NewReader(gosum))
+	for n := 1; s.Scan(); n++ {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("create-go-app: go.sum line %d: expected 'path version hash'", n)
+		}
+
+		version, goMod := strings.CutSuffix(fields[1], "/go.mod")
+		key := fields[0] + "@" + version
+		if i, ok := seen[key]; ok {
+			sums[i].GoModOnly = sums[i].GoModOnly && goMod
+			continue
+		}
+		seen[key] = len(sums)
+		sums = append(sums, Sum{Path: fields[0], Version: version, GoModOnly: goMod})
+	}
+
+	sort.Slice(sums, func(i, j int) bool {
+		if sums[i].Path != sums[j].Path {
+			return sums[i].Path < sums[j].Path
+		}
+		return sums[i].Version < sums[j].Version
+	})
+	return sums, nil
+}
+
+// ProxyDir returns the directory to use as a file GOPROXY for dir, which
+// is either a module cache (GOMODCACHE) or already a GOPROXY directory.
+func ProxyDir(dir string) string {
+	download := filepath.Join(dir, "cache", "download")
+	if info, err := os.Stat(download); err == nil && info.IsDir() {
+		return download
+	}
+	return dir
+}
+
+// MissingError lists the modules a GOPROXY directory lacks.
+type MissingError struct {
+	Proxy   string
+	Modules []Sum
+}
+
+func (e *MissingError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "create-go-app: %d module(s) missing from %s, run 'go mod download' for them on a machine with network access:", len(e.Modules), e.Proxy)
+	for _, m := range e.Modules {
+		fmt.Fprintf(&b, "\n\t%s", m)
+	}
+	return b.String()
+}
+
+// Missing returns a *MissingError listing the modules in sums whose files
+// aren't in the GOPROXY directory proxy, or nil if none are missing.
+func Missing(proxy string, sums []Sum) error {
+	var missing []Sum
+	for _, s := range sums {
+		exts := []string{".mod", ".zip"}
+		if s.GoModOnly {
+			exts = exts[:1]
+		}
+		for _, ext := range exts {
+			p := filepath.Join(proxy, filepath.FromSlash(escape(s.Path)), "@v", escape(s.Version)+ext)
+			if _, err := os.Stat(p); err != nil {
+				missing = append(missing, s)
+				break
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return &MissingError{Proxy: proxy, Modules: missing}
+	}
+	return nil
+}
+
+// OfflineEnv returns the environment that makes the go command resolve
+// modules from the GOPROXY directory proxy only. Hashes are still checked
+// against go.sum, so the checksum database isn't needed.
+func OfflineEnv(proxy string) ([]string, error) {
+	abs, err := filepath.Abs(proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	p := filepath.ToSlash(abs)
+	if !strings.HasPrefix(p, "/") {
+		// Windows drive letter, e.g. file:///C:/modcache.
+		p = "/" + p
+	}
+	u := url.URL{Scheme: "file", Path: p}
+
+	return []string{
+		"GOPROXY=" + u.String(),
+		"GOSUMDB=off",
+		"GOTOOLCHAIN=local",
+		"GOFLAGS=-mod=mod",
+	}, nil
+}
+
+// escape returns the case-encoded form of a module path or version used in
+// module caches and proxies, where each upper case letter is replaced by '!'
+// and its lower case.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Download runs 'go mod download' in dir, which fetches the modules in
+// go.mod and checks them against go.sum.
+func (r *Runner) Download(ctx context.Context, dir string) error {
+	_, err := r.Run(ctx, dir, "go", "mod", "download")
+	return err
+}
+
+// GoEnv returns the value of the go environment variable key, e.g.
+// GOMODCACHE.
+func (r *Runner) GoEnv(ctx context.Context, key string) (string, error) {
+	out, err := r.Run(ctx, "", "go", "env", key)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,102 @@
+package gotools
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testSum = `github.com/gorilla/mux v1.8.1 h1:abc=
+github.com/gorilla/mux v1.8.1/go.mod h1:def=
+github.com/bsm/gomega v1.27.10/go.mod h1:ghi=
+github.com/BurntSushi/toml v1.3.2 h1:jkl=
+github.com/BurntSushi/toml v1.3.2/go.mod h1:mno=
+`
+
+func TestParseSum(t *testing.T) {
+	tests := []struct {
+		title   string
+		gosum   string
+		want    []Sum
+		wantErr bool
+	}{
+		{
+			title: "Module and go.mod hashes",
+			gosum: testSum,
+			want: []Sum{
+				{Path: "github.com/BurntSushi/toml", Version: "v1.3.2"},
+				{Path: "github.com/bsm/gomega", Version: "v1.27.10", GoModOnly: true},
+				{Path: "github.com/gorilla/mux", Version: "v1.8.1"},
+			},
+		},
+		{title: "Empty", gosum: "\n"},
+		{title: "Malformed line", gosum: "github.com/gorilla/mux v1.8.1\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got, err := ParseSum([]byte(tt.gosum))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissing(t *testing.T) {
+	proxy := t.TempDir()
+	for _, f := range []string{
+		"github.com/gorilla/mux/@v/v1.8.1.mod",
+		"github.com/gorilla/mux/@v/v1.8.1.zip",
+		"github.com/bsm/gomega/@v/v1.27.10.mod",
+		// Upper case letters are escaped in proxies and module caches.
+		"github.com/!burnt!sushi/toml/@v/v1.3.2.mod",
+	} {
+		p := filepath.Join(proxy, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sums, err := ParseSum([]byte(testSum))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Missing(proxy, sums)
+	var e *MissingError
+	if !errors.As(err, &e) {
+		t.Fatalf("got %v, want *MissingError", err)
+	}
+	want := []Sum{{Path: "github.com/BurntSushi/toml", Version: "v1.3.2"}}
+	if !reflect.DeepEqual(e.Modules, want) {
+		t.Errorf("got missing %v, want %v", e.Modules, want)
+	}
+
+	if err := Missing(proxy, sums[1:]); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+}
+
+func TestProxyDir(t *testing.T) {
+	modcache := t.TempDir()
+	download := filepath.Join(modcache, "cache", "download")
+	if err := os.MkdirAll(download, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := ProxyDir(modcache); got != download {
+		t.Errorf("got %s for a module cache, want %s", got, download)
+	}
+	if got := ProxyDir(download); got != download {
+		t.Errorf("got %s for a proxy directory, want %s", got, download)
+	}
+}
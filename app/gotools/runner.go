@@ -0,0 +1,122 @@
+package gotools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// maxStderr bounds how much of a command's stderr is kept for its Error.
+const maxStderr = 32 << 10
+
+// Runner runs commands in a directory. A command is killed together with
+// every process it started once its context is done, so an interrupt stops
+// e.g. 'go get' and the compilers and VCS tools it runs.
+type Runner struct {
+	// Stdout and Stderr receive the command's output while it runs. Output
+	// is discarded when nil.
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Env is appended to the environment of the current process.
+	Env []string
+
+	// WaitDelay bounds how long Run waits for output after the command was
+	// killed or exited. The zero value waits 5 seconds.
+	WaitDelay time.Duration
+}
+
+// Error is returned by Runner.Run when a command fails to start, exits
+// non-zero or is cancelled.
+type Error struct {
+	Cmd      []string
+	Dir      string
+	ExitCode int // -1 if the command didn't exit on its own
+	Stderr   string
+	Err      error
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "create-go-app: '%s' in %s", strings.Join(e.Cmd, " "), e.Dir)
+	if e.ExitCode >= 0 {
+		fmt.Fprintf(&b, " failed with exit code %d", e.ExitCode)
+	} else {
+		fmt.Fprintf(&b, " failed: %v", e.Err)
+	}
+	if s := strings.TrimSpace(e.Stderr); s != "" {
+		fmt.Fprintf(&b, "\n%s", s)
+	}
+	return b.String()
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Run runs name with args in dir and returns its stdout. On failure the
+// returned error is an *Error; it wraps ctx.Err() when the command was
+// killed because ctx is done.
+func (r *Runner) Run(ctx context.Context, dir string, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	if len(r.Env) > 0 {
+		cmd.Env = append(cmd.Environ(), r.Env...)
+	}
+	cmd.WaitDelay = r.WaitDelay
+	if cmd.WaitDelay == 0 {
+		cmd.WaitDelay = 5 * time.Second
+	}
+	killGroup(cmd)
+
+	var stdout bytes.Buffer
+	stderr := &tail{max: maxStderr}
+	cmd.Stdout = writers(&stdout, r.Stdout)
+	cmd.Stderr = writers(stderr, r.Stderr)
+
+	err := cmd.Run()
+	if err == nil {
+		return stdout.Bytes(), nil
+	}
+
+	e := &Error{
+		Cmd:      append([]string{name}, args...),
+		Dir:      dir,
+		ExitCode: -1,
+		Stderr:   stderr.String(),
+		Err:      err,
+	}
+	var exitErr *exec.ExitError
+	if ctx.Err() != nil {
+		e.Err = ctx.Err()
+	} else if errors.As(err, &exitErr) && exitErr.Exited() {
+		e.ExitCode = exitErr.ExitCode()
+	}
+	return stdout.Bytes(), e
+}
+
+func writers(w io.Writer, live io.Writer) io.Writer {
+	if live == nil {
+		return w
+	}
+	return io.MultiWriter(w, live)
+}
+
+// tail keeps the last max bytes written to it.
+type tail struct {
+	max int
+	buf []byte
+}
+
+func (t *tail) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.max; over > 0 {
+		t.buf = t.buf[over:]
+	}
+	return len(p), nil
+}
+
+func (t *tail) String() string { return string(t.buf) }
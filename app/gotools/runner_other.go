@@ -0,0 +1,9 @@
+//go:build !unix
+
+package gotools
+
+import "os/exec"
+
+// killGroup leaves cmd unchanged; cancelling it kills only the process
+// itself.
+func killGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,130 @@
+package gotools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain lets the test binary stand in for the commands run by the tests.
+func TestMain(m *testing.M) {
+	switch os.Getenv("GOTOOLS_TEST_HELPER") {
+	case "":
+		os.Exit(m.Run())
+	case "echo":
+		fmt.Fprint(os.Stdout, "out")
+		fmt.Fprint(os.Stderr, "err")
+	case "fail":
+		fmt.Fprint(os.Stderr, "go: something went wrong")
+		os.Exit(3)
+	case "spawn":
+		// A grandchild that inherits stdout and outlives its parent unless
+		// the whole process group is killed.
+		cmd := exec.Command(os.Args[0])
+		cmd.Env = append(os.Environ(), "GOTOOLS_TEST_HELPER=sleep")
+		cmd.Stdout = os.Stdout
+		if err := cmd.Start(); err != nil {
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stdout, "started")
+		cmd.Wait()
+	case "sleep":
+		time.Sleep(time.Minute)
+	}
+	os.Exit(0)
+}
+
+func helper(name string) *Runner {
+	return &Runner{Env: []string{"GOTOOLS_TEST_HELPER=" + name}}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		title      string
+		helper     string
+		wantStdout string
+		wantErr    bool
+		wantExit   int
+		wantStderr string
+	}{
+		{title: "Success", helper: "echo", wantStdout: "out"},
+		{title: "Exit code and stderr", helper: "fail", wantErr: true, wantExit: 3, wantStderr: "go: something went wrong"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			var liveOut, liveErr bytes.Buffer
+			r := helper(tt.helper)
+			r.Stdout = &liveOut
+			r.Stderr = &liveErr
+
+			out, err := r.Run(context.Background(), t.TempDir(), os.Args[0])
+			if string(out) != tt.wantStdout {
+				t.Errorf("got stdout %q, want %q", out, tt.wantStdout)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				if liveOut.String() != "out" || liveErr.String() != "err" {
+					t.Errorf("got streamed output %q and %q, want %q and %q", liveOut.String(), liveErr.String(), "out", "err")
+				}
+				return
+			}
+
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("got %T, want *Error", err)
+			}
+			if e.ExitCode != tt.wantExit {
+				t.Errorf("got exit code %d, want %d", e.ExitCode, tt.wantExit)
+			}
+			if e.Stderr != tt.wantStderr {
+				t.Errorf("got stderr %q, want %q", e.Stderr, tt.wantStderr)
+			}
+			if !strings.Contains(err.Error(), tt.wantStderr) || !strings.Contains(err.Error(), "exit code 3") {
+				t.Errorf("error %q doesn't mention the exit code and stderr", err)
+			}
+		})
+	}
+}
+
+func TestRunCancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process groups are only killed on unix")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := helper("spawn")
+	r.WaitDelay = time.Minute
+	r.Stdout = writerFunc(func(p []byte) (int, error) {
+		if bytes.Contains(p, []byte("started")) {
+			cancel()
+		}
+		return len(p), nil
+	})
+
+	start := time.Now()
+	_, err := r.Run(ctx, t.TempDir(), os.Args[0])
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	// The grandchild holds stdout open, so Run only returns before
+	// WaitDelay if it was killed too.
+	if d := time.Since(start); d > 30*time.Second {
+		t.Errorf("Run returned after %v, the process group wasn't killed", d)
+	}
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
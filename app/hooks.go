@@ -0,0 +1,53 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"create-go-app.dev/gotools"
+	"create-go-app.dev/templates"
+
+	"github.com/fatih/color"
+)
+
+// runHooks runs the hooks that apply to the selected components in order,
+// each in its directory below root, and stops at the first that fails.
+func runHooks(ctx context.Context, runner *gotools.Runner, root string, hooks []templates.Hook, components []string) error {
+	for _, h := range hooks {
+		if !h.Applies(components) {
+			continue
+		}
+
+		fmt.Fprintf(color.Output, "%s: %s\n", color.WhiteString(cmp.Or(h.Name, "Running")), color.CyanString(strings.Join(h.Run, " ")))
+
+		err := runHook(ctx, runner, templates.HookDir(root, h), h)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runHook runs h in dir with its environment, killing it once its timeout
+// passed.
+func runHook(ctx context.Context, runner *gotools.Runner, dir string, h templates.Hook) error {
+	r := *runner
+	r.Env = append(slices.Clip(runner.Env), h.Environ()...)
+
+	hookCtx := ctx
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		hookCtx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+
+	_, err := r.Run(hookCtx, dir, h.Run[0], h.Run[1:]...)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return fmt.Errorf("create-go-app: '%s' in %s timed out after %s", strings.Join(h.Run, " "), dir, h.Timeout)
+	}
+	return err
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"create-go-app.dev/gotools"
+	"create-go-app.dev/templates"
+)
+
+// TestMain lets the test binary stand in for the commands hooks run, and
+// for create-go-app itself.
+func TestMain(m *testing.M) {
+	switch os.Getenv("CREATE_GO_APP_TEST_HELPER") {
+	case "":
+		os.Exit(m.Run())
+	case "touch":
+		// Records that the hook ran in its directory.
+		err := os.WriteFile(os.Getenv("HOOK_FILE"), nil, 0644)
+		if err != nil {
+			os.Exit(1)
+		}
+	case "fail":
+		os.Exit(1)
+	case "sleep":
+		time.Sleep(time.Minute)
+	case "main":
+		// Runs create-go-app with the arguments of the test binary.
+		main()
+	}
+	os.Exit(0)
+}
+
+// hook returns a hook that runs the test binary as helper. The 'touch'
+// helper creates file in the hook's directory.
+func hook(helper string, file string) templates.Hook {
+	return templates.Hook{
+		Run: []string{os.Args[0]},
+		Env: map[string]string{"CREATE_GO_APP_TEST_HELPER": helper, "HOOK_FILE": file},
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	inNode := hook("touch", "node")
+	inNode.Dir = "node"
+	withRedis := hook("touch", "redis")
+	withRedis.Components = []string{"redis"}
+	withBoth := hook("touch", "both")
+	withBoth.Components = []string{"redis", "node"}
+
+	tests := []struct {
+		title      string
+		hooks      []templates.Hook
+		components []string
+		// Files the hooks created, by slash separated path.
+		want    []string
+		wantErr bool
+	}{
+		{
+			title: "In order",
+			hooks: []templates.Hook{hook("touch", "a"), hook("touch", "b")},
+			want:  []string{"a", "b"},
+		},
+		{
+			title: "In the hook's directory",
+			hooks: []templates.Hook{inNode},
+			want:  []string{"node/node"},
+		},
+		{
+			title:      "Selected components",
+			hooks:      []templates.Hook{withRedis, withBoth},
+			components: []string{"postgres", "redis"},
+			want:       []string{"redis"},
+		},
+		{
+			title: "Components not selected",
+			hooks: []templates.Hook{withRedis, withBoth},
+		},
+		{
+			title:   "Stop at the first failure",
+			hooks:   []templates.Hook{hook("touch", "a"), hook("fail", "b"), hook("touch", "c")},
+			want:    []string{"a"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			root := t.TempDir()
+			err := os.Mkdir(filepath.Join(root, "node"), 0755)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = runHooks(context.Background(), &gotools.Runner{}, root, tt.hooks, tt.components)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+
+			var got []string
+			err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				rel, err := filepath.Rel(root, path)
+				got = append(got, filepath.ToSlash(rel))
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("hooks created %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunHookTimeout(t *testing.T) {
+	h := hook("sleep", "")
+	h.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	err := runHook(context.Background(), &gotools.Runner{}, t.TempDir(), h)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("the hook was killed after %s", d)
+	}
+}
+
+func TestRunHookCanceled(t *testing.T) {
+	h := hook("sleep", "")
+	h.Timeout = time.Minute
+
+	// An interrupt isn't reported as the hook's timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := runHook(ctx, &gotools.Runner{}, t.TempDir(), h)
+	if err == nil || strings.Contains(err.Error(), "timed out") {
+		t.Errorf("err = %v, want the context's error", err)
+	}
+}
@@ -0,0 +1,64 @@
+// Package license has the licenses a generated app can be released under.
+package license
+
+import (
+	"embed"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Names of the licenses, as SPDX identifiers.
+const (
+	MIT    = "MIT"
+	Apache = "Apache-2.0"
+	// None leaves the LICENSE file out.
+	None = "none"
+)
+
+// Names are the licenses in the order they are offered, the default first.
+var Names = []string{MIT, Apache, None}
+
+//go:embed MIT Apache-2.0
+var texts embed.FS
+
+// Parse returns the name of the license name, matched ignoring case.
+func Parse(name string) (string, error) {
+	for _, n := range Names {
+		if strings.EqualFold(n, strings.TrimSpace(name)) {
+			return n, nil
+		}
+	}
+	return "", fmt.Errorf("create-go-app: invalid license '%s', expected %s", name, list())
+}
+
+// Text returns the text of the license name with the copyright holder and
+// year filled in, which is empty for None.
+func Text(name string, holder string, year int) (string, error) {
+	name, err := Parse(name)
+	if err != nil || name == None {
+		return "", err
+	}
+
+	b, err := texts.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+
+	// The placeholders of the MIT text and of the Apache License's notice.
+	r := strings.NewReplacer(
+		"[year]", strconv.Itoa(year),
+		"[fullname]", holder,
+		"[yyyy]", strconv.Itoa(year),
+		"[name of copyright owner]", holder,
+	)
+	return r.Replace(string(b)), nil
+}
+
+func list() string {
+	quoted := make([]string, len(Names))
+	for i, n := range Names {
+		quoted[i] = "'" + n + "'"
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
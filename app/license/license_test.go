@@ -0,0 +1,54 @@
+package license
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "MIT", want: MIT},
+		{name: "apache-2.0", want: Apache},
+		{name: " None ", want: None},
+		{name: "GPL-3.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got = %v, want = %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got = %q, want = %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: MIT, want: "Copyright (c) 2026 Jane Doe\n"},
+		{name: Apache, want: "Copyright 2026 Jane Doe\n"},
+		{name: None, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Text(tt.name, "Jane Doe", 2026)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(got, tt.want) || (tt.want == "") != (got == "") {
+				t.Errorf("got = %.40q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
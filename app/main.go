@@ -1,19 +1,35 @@
 package main
 
 import (
+	"cmp"
+	"context"
 	"embed"
 	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"io/fs"
+	"maps"
 	"os"
+	"os/exec"
 	"os/signal"
+	"path"
 	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
 
+	"create-go-app.dev/component"
 	"create-go-app.dev/fsys"
 	"create-go-app.dev/gotools"
+	"create-go-app.dev/license"
+	"create-go-app.dev/manifest"
+	"create-go-app.dev/modpath"
+	"create-go-app.dev/overlay"
+	"create-go-app.dev/plan"
+	"create-go-app.dev/templates"
 	"create-go-app.dev/timer"
+	"create-go-app.dev/tmpl"
 
 	"github.com/fatih/color"
 	_ "github.com/joho/godotenv/autoload"
@@ -22,75 +38,158 @@ import (
 // 'create-go-app/embed'
 const EMBED_PATH = "embed"
 
-//go:embed all:embed
+// 'create-go-app/embed_cli'
+const EMBED_CLI_PATH = "embed_cli"
+
+// Go version of the app's module and Docker image, the one the pinned
+// go.sum is tested with, unless -go-version or the config sets another.
+const DEFAULT_GO_VERSION = "1.23.5"
+
+//go:embed all:embed all:embed_cli
 var emb embed.FS
 
-var ErrDirExists = errors.New("create-go-app: directory already exists")
+// Generator version recorded in the manifest of generated apps. Release
+// builds set it with -ldflags '-X main.version=v1.2.3'.
+var version = ""
+
+var ErrMissingModule = errors.New("create-go-app: a module name is required in non-interactive mode, pass -module, set CREATE_GO_APP_MODULE or configure a modulePrefix")
 
 const exampleRepoURL = "github.com/username/repo"
 
 type app struct {
-	appName  string
+	// Project name the templates are rendered with, e.g. in docker-compose.yml.
+	appName string
+	// Target directory as given on the command line, e.g. '.' or 'services/api'.
+	dir string
+	// Absolute path of the target directory.
 	fullPath string
-	embed    embedded
+	embed    fs.FS
 	timer    timer.Timer
+	staging  staging
 }
 
-type embedded struct {
-	fs embed.FS
-}
+var strFlag = flag.String("type", "http", "'http' or 'cli'")
 
-func (e embedded) Open(name string) (fsys.FileReaderCloser, error) {
-	file, err := e.fs.Open(name)
-	if err != nil {
-		return nil, err
-	}
+var moduleFlag = flag.String("module", "", "module path, e.g. 'github.com/username/my-app' (default $CREATE_GO_APP_MODULE)")
 
-	return file, nil
-}
+var portFlag = flag.Int("port", tmpl.DefaultPorts().Go, "host port the Go server is published on in docker-compose.yml")
 
-type fileService struct{}
+var withFlag = flag.String("with", "", "comma separated components to include, e.g. 'postgres,redis' or 'none' (default all)")
 
-func (f fileService) Create(name string) (*os.File, error) {
-	return os.Create(name)
-}
+var withoutFlag = flag.String("without", "", "comma separated components to leave out, e.g. 'node,playwright'")
 
-func (f fileService) WriteFile(name string, data []byte, perm os.FileMode) error {
-	return os.WriteFile(name, data, perm)
-}
+var dryRunFlag = flag.Bool("dry-run", false, "print the files and commands generating the app would write and run, without writing anything")
 
-func (f fileService) ReadAll(r io.Reader) ([]byte, error) {
-	return io.ReadAll(r)
-}
+var jsonFlag = flag.Bool("json", false, "with -dry-run, print the plan as JSON")
 
-func (f fileService) Mkdir(name string, perm os.FileMode) error {
-	return os.Mkdir(name, perm)
-}
+var yesFlag = flag.Bool("yes", false, "non-interactive mode, never prompt and fail if a required input is missing")
+
+var offlineFlag = flag.Bool("offline", false, "resolve dependencies from the pinned go.sum and -goproxy instead of the network")
+
+var goproxyFlag = flag.String("goproxy", "", "with -offline, a module cache or file GOPROXY directory (default $GOMODCACHE)")
+
+var latestFlag = flag.Bool("latest", false, "upgrade the pinned dependencies to their latest versions and report which moved")
+
+var onConflictFlag = flag.String("on-conflict", conflictFail, "what to do with generated files that already exist in the app's directory: 'skip', 'overwrite', 'prompt' or 'fail'")
+
+var logFlag = flag.String("log", "", "write the output of the go commands to this file instead of the terminal")
+
+var outputFlag = flag.String("output", "", "write the app to this .tar.gz, .tgz or .zip archive instead of a directory, under the project name")
+
+var nameFlag = flag.String("name", "", "project name used in the generated files (default the target directory's name)")
+
+var licenseFlag = flag.String("license", license.MIT, "license of the app: 'MIT', 'Apache-2.0' or 'none'")
+
+var authorFlag = flag.String("author", "", "copyright holder in the app's license (default git config user.name)")
+
+var goVersionFlag = flag.String("go-version", "", "Go version of the app's module, e.g. '1.23.5' (default "+DEFAULT_GO_VERSION+", the version go.sum is tested with)")
 
-type fileReaderWriter struct{}
+var templateFlag = flag.String("template", "", "template directory or registered name to generate from, layered over its base type's templates; -type is ignored")
 
-func (f fileReaderWriter) ReadFile(name string) ([]byte, error) {
-	return os.ReadFile(name)
+var varFlag = vars{}
+
+var overlayFlag overlayDirs
+
+var noHooksFlag = flag.Bool("no-hooks", false, "don't run the template's pre and post hooks")
+
+var explainFlag = flag.Bool("explain", false, "print which template or overlay each generated file comes from, without writing anything")
+
+func init() {
+	flag.Var(varFlag, "var", "with -template, a template variable as name=value, may be repeated")
+	flag.Var(&overlayFlag, "overlay", "directory whose files replace, patch ('<file>.patch') or delete ('<file>.delete') the templates' files, may be repeated with the last on top")
 }
 
-func (f fileReaderWriter) WriteFile(name string, data []byte, perm os.FileMode) error {
-	return os.WriteFile(name, data, perm)
+// projectType describes the embedded template set for a '-type' value.
+type projectType struct {
+	// Embedded directory the project is generated from.
+	embedPath string
+	// Directory of the Go module relative to the app's root directory.
+	moduleDir string
+	// Optional components the user can opt in or out of.
+	components component.Set
+	// Whether the project has the example resource.
+	exampleResource bool
+	// Whether docker-compose.yml publishes the services on host ports.
+	ports bool
+	// Shown when the user picks a type.
+	summary string
 }
 
-var strFlag = flag.String("type", "http", "'http' or 'cli'")
+var projectTypes = map[string]projectType{
+	"http": {
+		embedPath:       EMBED_PATH,
+		moduleDir:       "go",
+		exampleResource: true,
+		ports:           true,
+		summary:         "HTTP server with Docker Compose services",
+		components: component.Set{
+			{Name: "postgres", Summary: "Postgres database and the Go service that queries it", Paths: []string{"postgres", "go/postgres"}},
+			{Name: "redis", Summary: "Redis cache and Go client", Paths: []string{"go/redis"}},
+			{Name: "swagger", Summary: "OpenAPI spec with Swagger UI, editor and CI validation", Paths: []string{"swagger.yaml.tmpl", ".github"}},
+			{Name: "node", Summary: "Node browser client", Paths: []string{"node"}},
+			{Name: "playwright", Summary: "Playwright end-to-end tests against the Node client", Paths: []string{"playwright"}, Requires: []string{"node"}},
+		},
+	},
+	"cli": {
+		embedPath: EMBED_CLI_PATH,
+		moduleDir: ".",
+		summary:   "Command line app",
+	},
+}
 
 func NewApp(embed embed.FS, timer timer.Timer) app {
 	return app{
-		embed: embedded{embed},
+		embed: embed,
 		timer: timer,
 	}
 }
 
+// Subcommands, e.g. 'create-go-app generate resource'. Without one the app
+// generates a new project.
+var commands = map[string]func(args []string) error{
+	"generate": generate,
+	"upgrade":  upgrade,
+	"add":      add,
+	"template": templateCommand,
+	"config":   configCommand,
+}
+
 func main() {
 	color.NoColor = false
 
-	// Inject embed path.
-	fsys.EmbedPath = EMBED_PATH
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			err := cmd(os.Args[2:])
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			if err != nil {
+				fmt.Printf("%v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
 
 	start := timer.Start()
 
@@ -102,11 +201,15 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt)
 
+	// Cancelled on interrupt to stop run between steps.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// Wait for the app to complete or the app will wait for an interrupt signal.
 	done := make(chan error, 1)
 
 	go func() {
-		err := run(&a)
+		err := run(ctx, &a)
 		done <- err
 	}()
 
@@ -114,31 +217,37 @@ func main() {
 	select {
 	case <-sigChan:
 		fmt.Println("\nInterrupt received. Initiating cleanup...")
-		err := clean(a.fullPath)
+		cancel()
+		// Wait for run to stop writing before removing its files. run can't
+		// return while it waits on a prompt for its inputs, but then nothing
+		// was written and, with ctx cancelled, nothing will be. Prompts
+		// after writing to the staging directory stop on ctx.
+		if a.staging.started() {
+			<-done
+		}
+		err := a.staging.remove()
 		if err != nil {
 			fmt.Println("cleanup failed: ", err)
 			os.Exit(1)
 		}
 		fmt.Println("Cleanup complete, exiting.")
+		os.Exit(1)
 	case err := <-done:
 		if err != nil {
-			if errors.Is(ErrDirExists, err) {
-				fmt.Printf("create-go-app: directory '%s' already exists\n", a.fullPath)
-				return
-			}
 			fmt.Printf("%v\n", err)
-			err := clean(a.fullPath)
+			err := a.staging.remove()
 			if err != nil {
 				fmt.Printf("%v\n", err)
-				return
 			}
-		} else {
+			// Exit non-zero so scripts and CI can detect the failure.
+			os.Exit(1)
+		} else if !*dryRunFlag && !*explainFlag {
 			fmt.Println("App logic completed successfully.")
 		}
 	}
 }
 
-func run(a *app) error {
+func run(ctx context.Context, a *app) error {
 	env := os.Getenv("CREATE_GO_APP_ENV")
 
 	// Assign our own custom usage handler.
@@ -147,8 +256,29 @@ func run(a *app) error {
 	// Parse the cmd line flags.
 	flag.Parse()
 
+	// The config files and the environment set the flags the user didn't.
+	err := applyConfig()
+	if err != nil {
+		return err
+	}
+
 	// Flags come before non-flag arguments.
-	// fmt.Printf("flags: %s\n", *strFlag)
+	if _, ok := projectTypes[*strFlag]; !ok {
+		return fmt.Errorf("create-go-app: invalid type '%s', expected 'http' or 'cli'", *strFlag)
+	}
+
+	licenseName, err := license.Parse(*licenseFlag)
+	if err != nil {
+		return err
+	}
+	*licenseFlag = licenseName
+
+	if *goVersionFlag != "" {
+		err = tmpl.CheckGoVersion(*goVersionFlag)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Get all non-flag arguments passed to the program.
 	nonFlagArgs := flag.Args()
@@ -158,89 +288,318 @@ func run(a *app) error {
 		return fmt.Errorf("create-go-app: invalid non-flag arguments")
 	}
 
-	// Assign the last and only non-flag argument to the apps's root directory name.
-	a.appName = nonFlagArgs[0]
+	// The last and only non-flag argument is the app's target directory,
+	// e.g. 'my-app', '.', 'services/api' or '/srv/api'.
+	a.dir = nonFlagArgs[0]
 
-	// Get the working directory to show the user where the app is being created.
-	wkdir, err := os.Getwd()
+	// The absolute path shows the user where the app is being created.
+	fullPath, err := filepath.Abs(a.dir)
 	if err != nil {
 		return err
 	}
+	a.fullPath = fullPath
 
-	a.fullPath = filepath.Join(wkdir, a.appName)
+	a.appName, err = projectName(a.fullPath, *nameFlag)
+	if err != nil {
+		return err
+	}
 
-	if info, err := os.Stat(a.fullPath); err == nil && info.IsDir() {
-		return ErrDirExists
+	err = checkConflictPolicy(*onConflictFlag, !*yesFlag)
+	if err != nil {
+		return err
 	}
 
-	fmt.Fprintf(color.Output, "Creating a new %s app in %s\n", color.CyanString("Go"), color.YellowString(a.fullPath))
+	// An archive is generated in a temporary directory, the target is only
+	// its name.
+	where := a.fullPath
+	if *outputFlag != "" {
+		err = checkArchiveName(*outputFlag)
+		if err != nil {
+			return err
+		}
+		where = *outputFlag
+	} else if info, err := os.Stat(a.fullPath); err == nil && !info.IsDir() {
+		return fmt.Errorf("create-go-app: '%s' exists and is not a directory", a.fullPath)
+	}
 
-	moduleName, err := gotools.EnterModuleName()
+	// A user-supplied template replaces the type's embedded templates.
+	var (
+		src         fs.FS = a.embed
+		tpl         *templates.Template
+		tplType     projectType
+		templateRef string
+	)
+	if *templateFlag != "" {
+		tpl, templateRef, err = loadTemplate(*templateFlag)
+		if err != nil {
+			return err
+		}
+
+		tplType, src, err = templateType(tpl, a.embed)
+		if err != nil {
+			return err
+		}
+	} else if len(varFlag) > 0 {
+		return fmt.Errorf("create-go-app: -var needs -template")
+	}
+
+	// The wizard sets the flags the user didn't, e.g. -type.
+	ports, err := askOptions(ctx, a.appName, tpl, tplType)
 	if err != nil {
 		return err
 	}
 
-	// Walk the whole 'emit' directory and dynamically create the directories and files.
-	err = fs.WalkDir(a.embed.fs, ".", func(path string, d fs.DirEntry, err error) error {
+	pt, typeName := projectTypes[*strFlag], *strFlag
+	if tpl != nil {
+		pt, typeName = tplType, tpl.Name
+	}
+
+	// Overlays are stacked over the templates in memory, before anything is
+	// rendered.
+	var stack *overlay.Stack
+	var overlays []string
+	if len(overlayFlag) > 0 || *explainFlag {
+		stack, overlays, err = applyOverlays(src, pt, overlayFlag)
 		if err != nil {
 			return err
 		}
-		ops := fileService{}
-		return fsys.Output(a.appName, path, d.IsDir(), a.embed, ops)
-	})
+		src = stack
+	}
+
+	// Inject embed path.
+	fsys.EmbedPath = pt.embedPath
 
+	// Keep stdout valid JSON for tooling.
+	if !(*dryRunFlag && *jsonFlag) {
+		fmt.Fprintf(color.Output, "Creating a new %s %s app in %s\n", color.CyanString("Go"), typeName, color.YellowString(where))
+	}
+
+	moduleName, err := resolveModuleName(*moduleFlag, a.appName)
 	if err != nil {
 		return err
 	}
 
-	// Now that the directory is created from via fs.WalkDir, walk the newly created dir
-	// and update all import paths with the user's provided module string.
-	err = filepath.WalkDir(a.appName, func(path string, d fs.DirEntry, err error) error {
+	// Validate the module path before any files are written.
+	err = modpath.Check(moduleName)
+	if err != nil {
+		return err
+	}
+
+	components, err := selectComponents(pt.components)
+	if err != nil {
+		return err
+	}
+
+	if *explainFlag {
+		return explain(os.Stdout, stack, pt, components, *licenseFlag, baseLayer(typeName, tpl))
+	}
+
+	data := templateData(a.appName, moduleName, components, ports, *licenseFlag, resolveAuthor(*authorFlag, a.appName))
+
+	var pre, post []templates.Hook
+	if tpl != nil {
+		data.Vars, err = templateValues(tpl, varFlag, !*yesFlag)
 		if err != nil {
 			return err
 		}
-		ops := fileReaderWriter{}
-		return fsys.ReplaceImports("*.go", path, exampleRepoURL, moduleName, d, ops)
-	})
+		if !*noHooksFlag {
+			pre, post = tpl.Pre, tpl.Post
+		}
+	}
+
+	// Fail before writing anything when a pinned module isn't available.
+	var proxy string
+	if *offlineFlag {
+		proxy, err = offlineDeps(ctx, src, pt, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	m := newManifest(pt, moduleName, data)
+	if tpl != nil {
+		m.Options.Type = tpl.Base
+		m.Options.Template = templateRef
+	}
+	m.Options.Overlays = overlays
+
+	if *dryRunFlag {
+		return dryRun(ctx, a, src, pt, moduleName, components, data, m, pre, post)
+	}
+
+	runner, closeLog, err := newRunner(*logFlag)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	if *offlineFlag {
+		runner.Env, err = gotools.OfflineEnv(proxy)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Render in memory, the go commands below need the files on disk.
+	out := fsys.NewMem()
+	err = render(ctx, src, pt, components, data, out, m)
+	if err != nil {
+		return err
+	}
+
+	// Existing files in the target directory are only touched according to
+	// -on-conflict, decided before any go command or hook runs.
+	var conflicts []string
+	keep := map[string]bool{}
+	if *outputFlag == "" {
+		conflicts, err = conflictingFiles(out, a.fullPath)
+		if err != nil {
+			return err
+		}
+
+		keep, err = resolveConflicts(ctx, conflicts, a.fullPath, *onConflictFlag)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Generate into a staging directory that is renamed to a.fullPath once
+	// everything succeeded.
+	target := a.fullPath
+	if *outputFlag != "" {
+		target = filepath.Join(os.TempDir(), a.appName)
+	}
+
+	stagingPath, err := a.staging.create(ctx, target)
+	if err != nil {
+		return err
+	}
 
+	// The pre hooks run in the working directory before the app's files are
+	// written, so a failing one, e.g. checking for a tool, leaves nothing
+	// behind. The staging directory exists, so an interrupt waits for them.
+	err = runHooks(ctx, runner, ".", pre, components)
 	if err != nil {
 		return err
 	}
 
-	p := filepath.Join(a.appName, "go")
+	err = fsys.Copy(fsys.OS(stagingPath), out)
+	if err != nil {
+		return err
+	}
+
+	p := filepath.Join(stagingPath, pt.moduleDir)
 
 	_, err = os.Stat(p)
 
 	if errors.Is(err, fs.ErrNotExist) {
 		if env == "development" {
-			return errors.Join(err, fmt.Errorf("create-go-app: did you remove embed/go/go.mod before running the app"))
+			return errors.Join(err, fmt.Errorf("create-go-app: did you remove %s/go.mod before running the app", filepath.Join(pt.embedPath, pt.moduleDir)))
 		}
 		return err
 	}
 
-	err = os.Chdir(p)
-	if err != nil {
+	// go.mod and go.sum are rendered from the type's templates, which pin
+	// the tested dependency versions.
+	switch {
+	case !isModule(p):
+		// A template doesn't have to generate a Go module.
+	case *latestFlag:
+		fmt.Fprintf(color.Output, "%s %s\n", color.WhiteString("Upgrading dependencies:"), color.CyanString("go get -u ./..."))
+
+		moves, err := upgradeDeps(ctx, runner, p)
+		if err != nil {
+			return err
+		}
+		printMoves(moves)
+	case *offlineFlag:
+		fmt.Fprintf(color.Output, "%s %s\n", color.WhiteString("Downloading pinned dependencies from"), color.CyanString(proxy))
+
+		err = runner.Download(ctx, p)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	_, err = gotools.InitializeModule(moduleName)
+	if isModule(p) {
+		_, err = runner.FormatCode(ctx, p)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(color.Output, "%s: %s\n", color.WhiteString("Formatting code"), color.CyanString("go fmt ./..."))
+	}
+
+	// The template's post hooks run on the formatted app.
+	err = runHooks(ctx, runner, stagingPath, post, components)
 	if err != nil {
 		return err
 	}
 
-	fmt.Fprintf(color.Output, "%s %s\n", color.WhiteString("Fetching dependencies:"), color.CyanString("go get ./..."))
+	if *outputFlag == "" {
+		// Formatting, upgrading and the hooks may have changed or added
+		// files, which are decided on the same way.
+		late, err := conflictingFiles(os.DirFS(stagingPath), a.fullPath)
+		if err != nil {
+			return err
+		}
+		late = slices.DeleteFunc(late, func(c string) bool { return slices.Contains(conflicts, c) })
+
+		more, err := resolveConflicts(ctx, late, a.fullPath, *onConflictFlag)
+		if err != nil {
+			return err
+		}
+		maps.Copy(keep, more)
+	}
+
+	// Kept files are the user's, not the generator's.
+	for c, kept := range keep {
+		if kept {
+			m.Remove(c)
+		}
+	}
+
+	// Checksum the files as they were left, after formatting and upgrading.
+	err = m.Hash(os.DirFS(stagingPath))
+	if err != nil {
+		return err
+	}
 
-	err = gotools.GetAllDeps()
+	err = m.Write(stagingPath)
 	if err != nil {
 		return err
 	}
 
-	_, err = gotools.FormatCode()
+	err = m.SaveBase(fsys.OS(stagingPath))
 	if err != nil {
 		return err
 	}
 
-	fmt.Fprintf(color.Output, "%s: %s\n", color.WhiteString("Formatting code"), color.CyanString("go fmt ./..."))
+	if *outputFlag != "" {
+		// Entries are stamped with the manifest's time, so the archive's
+		// contents match what it records.
+		err = writeArchive(stagingPath, *outputFlag, a.appName, m.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		err = a.staging.remove()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(color.Output, "%s\n", color.GreenString(fmt.Sprintf("Wrote %s in %f seconds", *outputFlag, a.timer.Elapsed().Seconds())))
+		return nil
+	}
+
+	err = a.staging.commit(a.fullPath, keep)
+	if err != nil {
+		return err
+	}
 
 	elapsed := a.timer.Elapsed()
 
@@ -249,24 +608,448 @@ func run(a *app) error {
 	return nil
 }
 
-func usage() {
-	fmt.Printf("  To create an http server with the name 'my-app' run:\n")
-	fmt.Printf("  go run create-go-app.dev@latest -http my-app\n")
-	fmt.Printf("  The last argument must be the name. e.g. 'my-app'\n")
-	fmt.Printf("  Available named flag arguments: cli or http\n")
-	flag.PrintDefaults()
-}
+// render writes the files of the type's embedded templates for the
+// selected components to dst, rewrites the placeholder imports and records
+// each file in m.
+func render(ctx context.Context, src fs.FS, pt projectType, components []string, data tmpl.Data, dst fsys.FS, m *manifest.Manifest) error {
+	// Inject embed path.
+	fsys.EmbedPath = pt.embedPath
+
+	// Walk the type's embedded directory and dynamically create the directories and files.
+	err := fs.WalkDir(src, pt.embedPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// Skip the subtrees of components the user left out.
+		if rel, _ := filepath.Rel(pt.embedPath, path); skipped(pt, filepath.ToSlash(rel), components, data.License) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			err := recordTemplate(m, src, path)
+			if err != nil {
+				return err
+			}
+		}
+		return fsys.Output(dst, path, d.IsDir(), src, data)
+	})
 
-func clean(path string) error {
-	_, err := os.Stat(path)
 	if err != nil {
 		return err
 	}
 
-	err = os.RemoveAll(path)
+	// Now that the files are written, walk them and update all import paths
+	// with the user's provided module string.
+	return fs.WalkDir(dst, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return fsys.ReplaceImports("*.go", path, exampleRepoURL, data.ModulePath, d, dst)
+	})
+}
+
+// generatorVersion returns the version of this build of create-go-app.
+func generatorVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// newManifest returns the manifest of an app generated with the options,
+// without any files yet.
+func newManifest(pt projectType, moduleName string, data tmpl.Data) *manifest.Manifest {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	// Only the http type has the example resource.
+	resources := []string{}
+	if pt.exampleResource {
+		for _, r := range data.Resources {
+			resources = append(resources, r.Name)
+		}
+	}
+
+	components := append([]string{}, data.Components...)
+
+	return &manifest.Manifest{
+		Generator: manifest.Generator{Version: generatorVersion()},
+		Options: manifest.Options{
+			Type:       *strFlag,
+			Name:       data.AppName,
+			Module:     moduleName,
+			GoVersion:  data.GoVersion,
+			Components: components,
+			Resources:  resources,
+			Ports:      data.Ports,
+			License:    data.License,
+			Author:     data.Author,
+			Year:       data.Year,
+			Latest:     *latestFlag,
+			Offline:    *offlineFlag,
+			Vars:       data.Vars,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// recordTemplate adds the file generated from the embedded template at path
+// to m. Its checksum is set once generating is done.
+func recordTemplate(m *manifest.Manifest, src fs.FS, path string) error {
+	b, err := fs.ReadFile(src, path)
 	if err != nil {
 		return err
 	}
 
+	m.Add(manifest.File{
+		Path:           fsys.Name(path),
+		Source:         path,
+		TemplateSHA256: manifest.Checksum(b),
+	})
 	return nil
 }
+
+// dryRun prints what run would write and execute for the same inputs, as a
+// tree or as JSON, including the manifest m and the base copies.
+func dryRun(ctx context.Context, a *app, src fs.FS, pt projectType, moduleName string, components []string, data tmpl.Data, m *manifest.Manifest, pre []templates.Hook, post []templates.Hook) error {
+	p, err := plan.Build(src, plan.Options{
+		Name:      a.dir,
+		EmbedPath: pt.embedPath,
+		OldModule: exampleRepoURL,
+		NewModule: moduleName,
+		Data:      data,
+		Skip: func(rel string) bool {
+			return skipped(pt, rel, components, data.License)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = planGenerated(ctx, p, src, pt, components, data, m)
+	if err != nil {
+		return err
+	}
+
+	// The go commands only run on a Go module, see isModule.
+	moduleDir := filepath.Join(a.dir, pt.moduleDir)
+	gomod := path.Join(p.Root, pt.moduleDir, "go.mod")
+	if slices.ContainsFunc(p.Entries, func(e plan.Entry) bool { return e.Path == gomod }) {
+		switch {
+		case *latestFlag:
+			p.AddStep(moduleDir, "go", "get", "-u", "./...")
+			p.AddStep(moduleDir, "go", "mod", "tidy")
+		case *offlineFlag:
+			p.AddStep(moduleDir, "go", "mod", "download")
+		}
+		p.AddStep(moduleDir, "go", "fmt", "./...")
+	}
+	for _, h := range pre {
+		if h.Applies(components) {
+			p.AddPreStep(templates.HookDir(".", h), h.Run...)
+		}
+	}
+	for _, h := range post {
+		if h.Applies(components) {
+			p.AddStep(templates.HookDir(a.dir, h), h.Run...)
+		}
+	}
+
+	if *jsonFlag {
+		return p.WriteJSON(os.Stdout)
+	}
+
+	return p.WriteTree(color.Output)
+}
+
+// planGenerated adds the files the generator writes besides the templates'
+// to p: the manifest m, the base copies and the base's ignore file. The app
+// is rendered in memory to write them like run does, before formatting.
+func planGenerated(ctx context.Context, p *plan.Plan, src fs.FS, pt projectType, components []string, data tmpl.Data, m *manifest.Manifest) error {
+	out := fsys.NewMem()
+	err := render(ctx, src, pt, components, data, out, m)
+	if err != nil {
+		return err
+	}
+
+	err = m.Hash(out)
+	if err != nil {
+		return err
+	}
+
+	err = m.SaveBase(out)
+	if err != nil {
+		return err
+	}
+
+	b, err := m.Encode()
+	if err != nil {
+		return err
+	}
+	p.AddFile(manifest.Name, len(b), fsys.FilePerm)
+
+	return fs.WalkDir(out, path.Dir(manifest.BaseDir), func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		p.AddFile(name, int(info.Size()), info.Mode().Perm())
+		return nil
+	})
+}
+
+// projectName returns the name of the project generated into the absolute
+// path target, which is name when set and the target's last element otherwise.
+func projectName(target string, name string) (string, error) {
+	if name != "" {
+		if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return "", fmt.Errorf("create-go-app: invalid -name '%s', expected a single path element", name)
+		}
+		return name, nil
+	}
+
+	name = filepath.Base(target)
+	if name == string(filepath.Separator) || name == "." {
+		return "", fmt.Errorf("create-go-app: can't derive a project name from '%s', pass -name", target)
+	}
+
+	return name, nil
+}
+
+// templateData returns the model the type's '.tmpl' files are rendered with.
+func templateData(appName string, moduleName string, components []string, ports tmpl.Ports, licenseName string, author string) tmpl.Data {
+	return tmpl.Data{
+		AppName:    appName,
+		ModulePath: moduleName,
+		GoVersion:  cmp.Or(*goVersionFlag, DEFAULT_GO_VERSION),
+		Components: components,
+		Resources:  []tmpl.Resource{{Name: "Thing", Plural: "Things"}},
+		Ports:      ports,
+		License:    licenseName,
+		Author:     author,
+		Year:       time.Now().Year(),
+	}
+}
+
+// skipped reports whether the template at rel, relative to the type's
+// embedded directory, isn't generated: it belongs to a component the user
+// left out, or is the license of an app without one.
+func skipped(pt projectType, rel string, components []string, licenseName string) bool {
+	if licenseName == license.None && (rel == "LICENSE" || rel == "LICENSE"+tmpl.Suffix) {
+		return true
+	}
+	return pt.components.Excluded(rel, components)
+}
+
+// selectComponents returns the components chosen with -with and -without.
+// When neither is set it returns the configured components, or every
+// component.
+func selectComponents(set component.Set) ([]string, error) {
+	if len(set) == 0 {
+		return nil, nil
+	}
+
+	var with, without []string
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "with":
+			with = component.Parse(f.Value.String())
+		case "without":
+			without = component.Parse(f.Value.String())
+		}
+	})
+
+	if with == nil && without == nil {
+		with = settings.Components
+	}
+
+	return set.Select(with, without)
+}
+
+// offlineDeps returns the file GOPROXY directory for -offline, and fails
+// listing every module pinned in the app's go.sum the directory lacks.
+func offlineDeps(ctx context.Context, files fs.FS, pt projectType, data tmpl.Data) (string, error) {
+	var sums []gotools.Sum
+	name := path.Join(pt.embedPath, pt.moduleDir, "go.sum"+tmpl.Suffix)
+	src, err := fs.ReadFile(files, name)
+	if err == nil {
+		var gosum []byte
+		gosum, err = tmpl.Render(name, src, data)
+		if err == nil {
+			sums, err = gotools.ParseSum(gosum)
+		}
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	dir := *goproxyFlag
+	if dir == "" {
+		// Quiet, so the path isn't printed.
+		dir, err = (&gotools.Runner{}).GoEnv(ctx, "GOMODCACHE")
+		if err != nil {
+			return "", err
+		}
+	}
+	proxy := gotools.ProxyDir(dir)
+
+	err = gotools.Missing(proxy, sums)
+	if err != nil {
+		return "", err
+	}
+
+	return proxy, nil
+}
+
+// isModule reports whether dir has a go.mod file.
+func isModule(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return err == nil && !info.IsDir()
+}
+
+// upgradeDeps upgrades the dependencies of the module in dir and returns
+// the requirements whose versions moved.
+func upgradeDeps(ctx context.Context, runner *gotools.Runner, dir string) ([]gotools.Move, error) {
+	gomod := filepath.Join(dir, "go.mod")
+	before, err := readRequirements(gomod)
+	if err != nil {
+		return nil, err
+	}
+
+	err = runner.UpgradeDeps(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	err = runner.Tidy(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := readRequirements(gomod)
+	if err != nil {
+		return nil, err
+	}
+
+	return gotools.Moved(before, after), nil
+}
+
+func readRequirements(gomod string) ([]gotools.Require, error) {
+	b, err := os.ReadFile(gomod)
+	if err != nil {
+		return nil, err
+	}
+	return gotools.Requirements(b)
+}
+
+func printMoves(moves []gotools.Move) {
+	if len(moves) == 0 {
+		fmt.Fprintf(color.Output, "%s\n", color.WhiteString("All pinned versions are the latest"))
+		return
+	}
+
+	for _, m := range moves {
+		switch {
+		case m.Old == "":
+			fmt.Fprintf(color.Output, "  %s %s %s\n", color.GreenString("add"), m.Path, m.New)
+		case m.New == "":
+			fmt.Fprintf(color.Output, "  %s %s %s\n", color.RedString("drop"), m.Path, m.Old)
+		default:
+			fmt.Fprintf(color.Output, "  %s %s %s => %s\n", color.YellowString("move"), m.Path, m.Old, m.New)
+		}
+	}
+}
+
+// newRunner returns a runner streaming the go commands' output to the
+// terminal, or to the file at logPath when it is set.
+func newRunner(logPath string) (*gotools.Runner, func() error, error) {
+	if logPath == "" {
+		return &gotools.Runner{Stdout: os.Stdout, Stderr: os.Stderr}, func() error { return nil }, nil
+	}
+
+	f, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &gotools.Runner{Stdout: f, Stderr: f}, f.Close, nil
+}
+
+// resolveModuleName returns the module path from the -module flag, then the
+// CREATE_GO_APP_MODULE environment variable, then the configured module
+// prefix followed by appName.
+func resolveModuleName(flagValue string, appName string) (string, error) {
+	if name := strings.TrimSpace(flagValue); name != "" {
+		return name, nil
+	}
+
+	if name := strings.TrimSpace(os.Getenv("CREATE_GO_APP_MODULE")); name != "" {
+		return name, nil
+	}
+
+	if name := settings.Module(appName); name != "" {
+		return name, nil
+	}
+
+	return "", ErrMissingModule
+}
+
+// resolveAuthor returns the license's copyright holder from the -author
+// flag, then git's user.name, then the authors of the app named appName.
+func resolveAuthor(flagValue string, appName string) string {
+	if name := strings.TrimSpace(flagValue); name != "" {
+		return name
+	}
+
+	// Quietly, git may not be installed or configured.
+	out, err := exec.Command("git", "config", "user.name").Output()
+	if name := strings.TrimSpace(string(out)); err == nil && name != "" {
+		return name
+	}
+
+	return defaultAuthor(appName)
+}
+
+// defaultAuthor is the copyright holder of the app named appName when none
+// is known.
+func defaultAuthor(appName string) string {
+	return "The " + appName + " Authors"
+}
+
+func usage() {
+	fmt.Printf("  To create an http server with the name 'my-app' run:\n")
+	fmt.Printf("  go run create-go-app.dev@latest -type=http my-app\n")
+	fmt.Printf("  To create a command line application with the name 'my-cli' run:\n")
+	fmt.Printf("  go run create-go-app.dev@latest -type=cli my-cli\n")
+	fmt.Printf("  To leave out components:\n")
+	fmt.Printf("  go run create-go-app.dev@latest -without=node,playwright my-app\n")
+	fmt.Printf("  To add a resource to a generated http project, run in its root directory:\n")
+	fmt.Printf("  go run create-go-app.dev@latest generate resource BlogPost title:string body:text\n")
+	fmt.Printf("  To generate from your own template directory:\n")
+	fmt.Printf("  go run create-go-app.dev@latest -template=./platform -var team=payments my-app\n")
+	fmt.Printf("  To generate without running the template's pre and post hooks:\n")
+	fmt.Printf("  go run create-go-app.dev@latest -template=./platform -no-hooks my-app\n")
+	fmt.Printf("  To stack your organization's files over the templates and see where each file comes from:\n")
+	fmt.Printf("  go run create-go-app.dev@latest -overlay ./org -explain my-app\n")
+	fmt.Printf("  To license the app under Apache 2.0 instead of MIT:\n")
+	fmt.Printf("  go run create-go-app.dev@latest -license=Apache-2.0 my-app\n")
+	fmt.Printf("  To name the copyright holder in the license:\n")
+	fmt.Printf("  go run create-go-app.dev@latest -author='Jane Doe' my-app\n")
+	fmt.Printf("  To print the defaults from the config files and the environment:\n")
+	fmt.Printf("  go run create-go-app.dev@latest config show\n")
+	fmt.Printf("  To run without prompts, e.g. in CI or a Dockerfile:\n")
+	fmt.Printf("  go run create-go-app.dev@latest -yes -module github.com/username/my-app my-app\n")
+	fmt.Printf("  The last argument must be the name. e.g. 'my-app'\n")
+	flag.PrintDefaults()
+}
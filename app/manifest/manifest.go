@@ -0,0 +1,251 @@
+// Package manifest records how an app was generated in a file at its root,
+// so later commands can tell what the generator wrote from what the user
+// changed since.
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"create-go-app.dev/fsys"
+	"create-go-app.dev/tmpl"
+)
+
+// Name is the manifest's file name in the app's root directory.
+const Name = ".create-go-app.json"
+
+// BaseDir holds a copy of every generated file as the generator left it,
+// at the same path relative to the app's root directory. It is the common
+// ancestor when newer templates are merged into files the user edited.
+// Files holding secrets aren't copied.
+const BaseDir = ".create-go-app/base"
+
+// BaseIgnore keeps BaseDir out of version control, as it duplicates the
+// app's files.
+const BaseIgnore = ".create-go-app/.gitignore"
+
+// Schema is the version of the manifest format written by this package.
+const Schema = 1
+
+// ErrNotFound is returned by Read when the directory has no manifest, e.g.
+// because it wasn't generated or was generated by an older version.
+var ErrNotFound = errors.New("create-go-app: no " + Name + " manifest, was the project generated by create-go-app?")
+
+// Manifest describes how an app was generated.
+type Manifest struct {
+	Schema    int       `json:"schema"`
+	Generator Generator `json:"generator"`
+	Options   Options   `json:"options"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Files are sorted by path.
+	Files []File `json:"files"`
+}
+
+// Generator identifies the create-go-app build that last wrote the
+// manifest.
+type Generator struct {
+	Version string `json:"version"`
+}
+
+// Options are the inputs the app was generated with.
+type Options struct {
+	Type       string     `json:"type"`
+	Name       string     `json:"name"`
+	Module     string     `json:"module"`
+	GoVersion  string     `json:"goVersion"`
+	Components []string   `json:"components"`
+	Resources  []string   `json:"resources"`
+	Ports      tmpl.Ports `json:"ports"`
+	Latest     bool       `json:"latest,omitempty"`
+	Offline    bool       `json:"offline,omitempty"`
+	// License of the app, empty for apps generated before it could be
+	// chosen, which are MIT licensed.
+	License string `json:"license,omitempty"`
+	// Copyright holder and year in the license, empty for apps generated
+	// before they were recorded.
+	Author string `json:"author,omitempty"`
+	Year   int    `json:"year,omitempty"`
+	// User-supplied template, its registered name or directory, and the
+	// values of its variables.
+	Template string            `json:"template,omitempty"`
+	Vars     map[string]string `json:"vars,omitempty"`
+	// Overlay directories stacked over the templates, bottom up.
+	Overlays []string `json:"overlays,omitempty"`
+}
+
+// File is a file the generator wrote.
+type File struct {
+	// Slash separated path relative to the app's root directory.
+	Path string `json:"path"`
+	// Embedded template the file was generated from, or the command that
+	// generated it, e.g. 'generate resource'.
+	Source string `json:"source"`
+	// Checksum of the embedded template, empty for generated files.
+	TemplateSHA256 string `json:"templateSha256,omitempty"`
+	// Checksum of the file as the generator left it.
+	SHA256 string `json:"sha256"`
+}
+
+// Checksum returns the hex encoded SHA-256 of b.
+func Checksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Read reads the manifest in the app directory dir.
+func Read(dir string) (*Manifest, error) {
+	b, err := os.ReadFile(filepath.Join(dir, Name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		return nil, fmt.Errorf("create-go-app: invalid %s: %w", Name, err)
+	}
+	if m.Schema > Schema {
+		return nil, fmt.Errorf("create-go-app: %s has schema %d, upgrade create-go-app to read it", Name, m.Schema)
+	}
+
+	return &m, nil
+}
+
+// Write writes the manifest to the app directory dir.
+func (m *Manifest) Write(dir string) error {
+	b, err := m.Encode()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, Name), b, fsys.FilePerm)
+}
+
+// Encode returns the contents of the manifest's file.
+func (m *Manifest) Encode() ([]byte, error) {
+	m.Schema = Schema
+	slices.SortFunc(m.Files, func(a, b File) int { return strings.Compare(a.Path, b.Path) })
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// Lookup returns the file at the slash separated path.
+func (m *Manifest) Lookup(path string) (File, bool) {
+	for _, f := range m.Files {
+		if f.Path == path {
+			return f, true
+		}
+	}
+	return File{}, false
+}
+
+// Add records f, replacing a file at the same path.
+func (m *Manifest) Add(f File) {
+	for i := range m.Files {
+		if m.Files[i].Path == f.Path {
+			m.Files[i] = f
+			return
+		}
+	}
+	m.Files = append(m.Files, f)
+}
+
+// Remove removes the file at the slash separated path, if it is recorded.
+func (m *Manifest) Remove(path string) {
+	m.Files = slices.DeleteFunc(m.Files, func(f File) bool { return f.Path == path })
+}
+
+// Hash sets the checksum of each file to the one of its contents in the
+// app's files. It is called once the generator is done with the files, e.g.
+// after formatting them.
+func (m *Manifest) Hash(files fs.FS) error {
+	for i, f := range m.Files {
+		b, err := fs.ReadFile(files, f.Path)
+		if err != nil {
+			return err
+		}
+		m.Files[i].SHA256 = Checksum(b)
+	}
+	return nil
+}
+
+// Modified reports whether the file at the slash separated path in the app
+// directory dir differs from what the generator wrote. Files the manifest
+// doesn't list are reported as modified.
+func (m *Manifest) Modified(dir string, path string) (bool, error) {
+	f, ok := m.Lookup(path)
+	if !ok {
+		return true, nil
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+	if err != nil {
+		return false, err
+	}
+	return Checksum(b) != f.SHA256, nil
+}
+
+// SaveBase copies each of the app's files to BaseDir, except for secrets.
+func (m *Manifest) SaveBase(files fsys.FS) error {
+	for _, f := range m.Files {
+		b, err := fs.ReadFile(files, f.Path)
+		if err != nil {
+			return err
+		}
+
+		err = WriteBase(files, f.Path, b)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Base returns the copy of the file at the slash separated path in the app
+// directory dir saved in BaseDir.
+func Base(dir string, path string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(dir, filepath.FromSlash(BaseDir), filepath.FromSlash(path)))
+}
+
+// WriteBase saves b as the copy of the app's file at the slash separated
+// name, with the permissions of the file, and writes BaseIgnore when it is
+// missing. Secrets, e.g. '.env', aren't saved, so upgrades only have their
+// checksums.
+func WriteBase(files fsys.FS, name string, b []byte) error {
+	if fsys.Secret(name) {
+		return nil
+	}
+
+	dst := path.Join(BaseDir, name)
+	err := files.MkdirAll(path.Dir(dst), fsys.DirPerm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fs.Stat(files, BaseIgnore); errors.Is(err, fs.ErrNotExist) {
+		err = files.WriteFile(BaseIgnore, []byte("/base/\n"), fsys.FilePerm)
+		if err != nil {
+			return err
+		}
+	}
+
+	return files.WriteFile(dst, b, fsys.Mode(name, b))
+}
@@ -0,0 +1,188 @@
+package manifest
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"create-go-app.dev/fsys"
+)
+
+func TestWriteRead(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	m := &Manifest{
+		Generator: Generator{Version: "v1.2.3"},
+		Options:   Options{Type: "http", Name: "app", Module: "example.com/app", Components: []string{"postgres"}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.Add(File{Path: "go/main.go", Source: "embed/go/main.go", SHA256: "b"})
+	m.Add(File{Path: ".env", Source: "embed/.env.tmpl", SHA256: "a"})
+	m.Add(File{Path: "go/main.go", Source: "embed/go/main.go", SHA256: "c"})
+
+	if err := m.Write(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Schema != Schema {
+		t.Errorf("got schema %d, want %d", got.Schema, Schema)
+	}
+
+	wantFiles := []File{
+		{Path: ".env", Source: "embed/.env.tmpl", SHA256: "a"},
+		{Path: "go/main.go", Source: "embed/go/main.go", SHA256: "c"},
+	}
+	if !reflect.DeepEqual(got.Files, wantFiles) {
+		t.Errorf("got files %v, want %v", got.Files, wantFiles)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("got %+v, want %+v", got, m)
+	}
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		title    string
+		contents string
+		want     error
+		wantErr  bool
+	}{
+		{title: "Missing", want: ErrNotFound, wantErr: true},
+		{title: "Invalid JSON", contents: "{", wantErr: true},
+		{title: "Newer schema", contents: `{"schema": 99}`, wantErr: true},
+		{title: "Valid", contents: `{"schema": 1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.contents != "" {
+				if err := os.WriteFile(filepath.Join(dir, Name), []byte(tt.contents), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := Read(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestModified(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{"same.go": "same", "edited.go": "edited", "unknown.go": ""} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := &Manifest{Files: []File{
+		{Path: "same.go", SHA256: Checksum([]byte("same"))},
+		{Path: "edited.go", SHA256: Checksum([]byte("original"))},
+	}}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "same.go", want: false},
+		{path: "edited.go", want: true},
+		{path: "unknown.go", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := m.Modified(dir, tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+
+	if err := m.Hash(os.DirFS(dir)); err != nil {
+		t.Fatal(err)
+	}
+	if modified, _ := m.Modified(dir, "edited.go"); modified {
+		t.Error("edited.go is modified after Hash")
+	}
+}
+
+func TestSaveBase(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go/main.go":    "package main\n",
+		".env":          "POSTGRES_PASSWORD=secret\n",
+		".env.example":  "POSTGRES_PASSWORD=\n",
+		"certs/tls.key": "key\n",
+	}
+
+	m := &Manifest{}
+	for path, contents := range files {
+		dst := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dst, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		m.Add(File{Path: path})
+	}
+
+	if err := m.SaveBase(fsys.OS(dir)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path  string
+		saved bool
+	}{
+		{path: "go/main.go", saved: true},
+		{path: ".env", saved: false},
+		{path: ".env.example", saved: true},
+		{path: "certs/tls.key", saved: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			b, err := Base(dir, tt.path)
+			if !tt.saved {
+				if !errors.Is(err, fs.ErrNotExist) {
+					t.Errorf("err = %v, want the secret not to be saved", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != files[tt.path] {
+				t.Errorf("got %q, want %q", b, files[tt.path])
+			}
+		})
+	}
+
+	ignore, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(BaseIgnore)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(ignore) != "/base/\n" {
+		t.Errorf("%s = %q", BaseIgnore, ignore)
+	}
+}
@@ -0,0 +1,230 @@
+// Package merge diffs and three-way merges text files line by line, to
+// carry changes to templates over into files users have since edited.
+package merge
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Labels name the three versions in conflict markers.
+type Labels struct {
+	Ours   string
+	Base   string
+	Theirs string
+}
+
+// Result is the outcome of a three-way merge.
+type Result struct {
+	Merged []byte
+	// Number of hunks both sides changed differently. Each is written to
+	// Merged between diff3 style conflict markers.
+	Conflicts int
+}
+
+// Three merges the changes from base to ours and from base to theirs. A hunk
+// only one side changed takes that side, a hunk both sides changed the same
+// way is taken once and anything else is a conflict.
+func Three(base, ours, theirs []byte, labels Labels) Result {
+	b, o, t := lines(base), lines(ours), lines(theirs)
+	mo := matches(b, o)
+	mt := matches(b, t)
+
+	var res Result
+	var out bytes.Buffer
+	ib, io, it := 0, 0, 0
+
+	for {
+		// Copy the lines that are unchanged on both sides.
+		n := 0
+		for ib+n < len(b) && mo[ib+n] == io+n && mt[ib+n] == it+n {
+			n++
+		}
+		writeLines(&out, b[ib:ib+n])
+		ib, io, it = ib+n, io+n, it+n
+
+		// The changed hunk ends at the next base line both sides kept.
+		next := ib
+		for next < len(b) && (mo[next] < 0 || mt[next] < 0) {
+			next++
+		}
+		eo, et := len(o), len(t)
+		if next < len(b) {
+			eo, et = mo[next], mt[next]
+		}
+
+		hb, ho, ht := b[ib:next], o[io:eo], t[it:et]
+		switch {
+		case equal(ho, hb):
+			writeLines(&out, ht)
+		case equal(ht, hb), equal(ho, ht):
+			writeLines(&out, ho)
+		default:
+			res.Conflicts++
+			conflict(&out, hb, ho, ht, labels)
+		}
+
+		if next == len(b) {
+			break
+		}
+		ib, io, it = next, eo, et
+	}
+
+	res.Merged = out.Bytes()
+	return res
+}
+
+func conflict(out *bytes.Buffer, base, ours, theirs []string, labels Labels) {
+	marker := func(m string, label string) {
+		ensureNewline(out)
+		fmt.Fprintf(out, "%s %s\n", m, label)
+	}
+	marker("<<<<<<<", labels.Ours)
+	writeLines(out, ours)
+	marker("|||||||", labels.Base)
+	writeLines(out, base)
+	ensureNewline(out)
+	out.WriteString("=======\n")
+	writeLines(out, theirs)
+	marker(">>>>>>>", labels.Theirs)
+}
+
+func ensureNewline(out *bytes.Buffer) {
+	if out.Len() > 0 && out.Bytes()[out.Len()-1] != '\n' {
+		out.WriteByte('\n')
+	}
+}
+
+// lines splits b after each newline. The last line has no newline if b
+// doesn't end with one.
+func lines(b []byte) []string {
+	var ls []string
+	for len(b) > 0 {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			i = len(b) - 1
+		}
+		ls = append(ls, string(b[:i+1]))
+		b = b[i+1:]
+	}
+	return ls
+}
+
+func writeLines(out *bytes.Buffer, ls []string) {
+	for _, l := range ls {
+		out.WriteString(l)
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// matches returns, for each line of a, the index of the line of b it is
+// paired with in a longest common subsequence, or -1. It uses Myers' linear
+// space algorithm, so large files such as lockfiles take memory in
+// proportion to their lengths rather than to their product.
+func matches(a, b []string) []int {
+	m := make([]int, len(a))
+	for i := range m {
+		m[i] = -1
+	}
+
+	n := len(a) + len(b) + 2
+	d := &differ{a: a, b: b, m: m, vf: make([]int, 2*n), vb: make([]int, 2*n)}
+	d.compare(0, len(a), 0, len(b))
+	return m
+}
+
+// differ pairs the lines of a and b in m. vf and vb hold how far the forward
+// and backward paths reach on each diagonal.
+type differ struct {
+	a, b   []string
+	m      []int
+	vf, vb []int
+}
+
+// compare pairs the lines of a[aLo:aHi] and b[bLo:bHi].
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.m[aLo] = bLo
+		aLo, bLo = aLo+1, bLo+1
+	}
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		aHi, bHi = aHi-1, bHi-1
+		d.m[aHi] = bHi
+	}
+	if aLo == aHi || bLo == bHi {
+		return
+	}
+
+	// Without a common first or last line the edit distance is at least 2,
+	// so both halves around the middle snake are shorter edits.
+	x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+	d.compare(aLo, x, bLo, y)
+	for ; x < u; x, y = x+1, y+1 {
+		d.m[x] = y
+	}
+	d.compare(u, aHi, v, bHi)
+}
+
+// middleSnake returns the start and end of the diagonal run of equal lines in
+// the middle of a shortest edit script from a[aLo:aHi] to b[bLo:bHi], where
+// the paths searched from both ends meet.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	// Diagonal k = x-y is at index off+k.
+	off := (n+m+1)/2 + 1
+	d.vf[off+1], d.vb[off+1] = 0, 0
+
+	for e := 0; e <= (n+m+1)/2; e++ {
+		// Forward from the start.
+		for k := -e; k <= e; k += 2 {
+			x := d.vf[off+k-1] + 1
+			if k == -e || (k != e && d.vf[off+k-1] < d.vf[off+k+1]) {
+				x = d.vf[off+k+1]
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x, y = x+1, y+1
+			}
+			d.vf[off+k] = x
+
+			if odd && k >= delta-(e-1) && k <= delta+(e-1) && x+d.vb[off+delta-k] >= n {
+				return aLo + x0, bLo + y0, aLo + x, bLo + y
+			}
+		}
+
+		// Backward from the end, counting lines from the end.
+		for k := -e; k <= e; k += 2 {
+			x := d.vb[off+k-1] + 1
+			if k == -e || (k != e && d.vb[off+k-1] < d.vb[off+k+1]) {
+				x = d.vb[off+k+1]
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x, y = x+1, y+1
+			}
+			d.vb[off+k] = x
+
+			if !odd && delta-k >= -e && delta-k <= e && x+d.vf[off+delta-k] >= n {
+				return aHi - x, bHi - y, aHi - x0, bHi - y0
+			}
+		}
+	}
+
+	// Unreachable, the paths meet within (n+m+1)/2 steps.
+	return aLo, bLo, aLo, bLo
+}
@@ -0,0 +1,311 @@
+package merge
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var labels = Labels{Ours: "ours", Base: "base", Theirs: "theirs"}
+
+func TestThree(t *testing.T) {
+	tests := []struct {
+		title         string
+		base          string
+		ours          string
+		theirs        string
+		want          string
+		wantConflicts int
+	}{
+		{
+			title:  "Unchanged",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nc\n",
+		},
+		{
+			title:  "Only theirs changed",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nB\nc\n",
+			want:   "a\nB\nc\n",
+		},
+		{
+			title:  "Only ours changed",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\nd\n",
+			theirs: "a\nb\nc\n",
+			want:   "a\nb\nc\nd\n",
+		},
+		{
+			title:  "Both changed different lines",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "A\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "A\nb\nc\nd\nE\n",
+		},
+		{
+			title:  "Both made the same change",
+			base:   "a\nb\nc\n",
+			ours:   "a\nX\nc\n",
+			theirs: "a\nX\nc\n",
+			want:   "a\nX\nc\n",
+		},
+		{
+			title:  "Insertions at different places",
+			base:   "a\nb\nc\n",
+			ours:   "0\na\nb\nc\n",
+			theirs: "a\nb\nc\nd\n",
+			want:   "0\na\nb\nc\nd\n",
+		},
+		{
+			title:  "Deletion and unrelated edit",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "a\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\n",
+			want:   "a\nc\nd\nE\n",
+		},
+		{
+			title:         "Conflict",
+			base:          "a\nb\nc\n",
+			ours:          "a\nours\nc\n",
+			theirs:        "a\ntheirs\nc\n",
+			want:          "a\n<<<<<<< ours\nours\n||||||| base\nb\n=======\ntheirs\n>>>>>>> theirs\nc\n",
+			wantConflicts: 1,
+		},
+		{
+			title:         "Conflict without trailing newlines",
+			base:          "a",
+			ours:          "b",
+			theirs:        "c",
+			want:          "<<<<<<< ours\nb\n||||||| base\na\n=======\nc\n>>>>>>> theirs\n",
+			wantConflicts: 1,
+		},
+		{
+			title:         "No base",
+			base:          "",
+			ours:          "a\n",
+			theirs:        "b\n",
+			want:          "<<<<<<< ours\na\n||||||| base\n=======\nb\n>>>>>>> theirs\n",
+			wantConflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got := Three([]byte(tt.base), []byte(tt.ours), []byte(tt.theirs), labels)
+			if string(got.Merged) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got.Merged, tt.want)
+			}
+			if got.Conflicts != tt.wantConflicts {
+				t.Errorf("got %d conflicts, want %d", got.Conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		title string
+		a     string
+		b     string
+	}{
+		{title: "Equal", a: "abc", b: "abc"},
+		{title: "Empty", a: "", b: "abc"},
+		{title: "Disjoint", a: "abc", b: "xyz"},
+		{title: "Inserted", a: "ac", b: "abc"},
+		{title: "Deleted", a: "abcd", b: "ad"},
+		{title: "Moved", a: "abcabba", b: "cbabac"},
+		{title: "Repeated", a: "aaaabaaaa", b: "aabaaaaaab"},
+		{title: "Odd difference", a: "xaybzc", b: "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			a, b := strings.Split(tt.a, ""), strings.Split(tt.b, "")
+			m := matches(a, b)
+
+			n, last := 0, -1
+			for i, j := range m {
+				if j < 0 {
+					continue
+				}
+				if j <= last || a[i] != b[j] {
+					t.Fatalf("line %d paired with %d in %v", i, j, m)
+				}
+				n, last = n+1, j
+			}
+			if want := lcsLength(a, b); n != want {
+				t.Errorf("paired %d lines, want %d", n, want)
+			}
+		})
+	}
+}
+
+func TestMatchesLarge(t *testing.T) {
+	// A lockfile sized input, too large for a table of every pair of lines.
+	var a, b []string
+	for i := range 200000 {
+		a = append(a, fmt.Sprintf("line %d\n", i))
+		if i%1000 != 0 {
+			b = append(b, fmt.Sprintf("line %d\n", i))
+		}
+	}
+	b = append(b, "added\n")
+
+	m := matches(a, b)
+	n := 0
+	for _, j := range m {
+		if j >= 0 {
+			n++
+		}
+	}
+	if n != len(b)-1 {
+		t.Errorf("paired %d lines, want %d", n, len(b)-1)
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b.
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		title string
+		a     string
+		b     string
+		want  string
+	}{
+		{title: "Equal", a: "a\nb\n", b: "a\nb\n", want: ""},
+		{
+			title: "Changed line",
+			a:     "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:     "1\n2\n3\n4\nfive\n6\n7\n8\n",
+			want:  "--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			title: "Separate hunks",
+			a:     "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			b:     "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			want:  "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			title: "Into empty file",
+			a:     "",
+			b:     "a\n",
+			want:  "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+		{
+			title: "No newline at end of file",
+			a:     "a",
+			b:     "b",
+			want:  "--- a\n+++ b\n@@ -1,1 +1,1 @@\n-a\n\\ No newline at end of file\n+b\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got := Unified("a", "b", []byte(tt.a), []byte(tt.b))
+			if string(got) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		title   string
+		b       string
+		patch   string
+		want    string
+		wantErr string
+	}{
+		{
+			title: "Changed line",
+			b:     "1\n2\n3\n4\n5\n6\n7\n8\n",
+			patch: "--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+			want:  "1\n2\n3\n4\nfive\n6\n7\n8\n",
+		},
+		{
+			title: "Moved down",
+			b:     "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			patch: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+			want:  "0\none\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+		},
+		{
+			title: "Into empty file",
+			b:     "",
+			patch: "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+a\n",
+			want:  "a\n",
+		},
+		{
+			title: "No newline at end of file",
+			b:     "a",
+			patch: "--- a\n+++ b\n@@ -1,1 +1,1 @@\n-a\n\\ No newline at end of file\n+b\n\\ No newline at end of file\n",
+			want:  "b",
+		},
+		{
+			title: "Git header and short range",
+			b:     "a\nb\n",
+			patch: "diff --git a/f b/f\nindex 1..2 100644\n--- a/f\n+++ b/f\n@@ -2 +2,2 @@\n b\n+c\n",
+			want:  "a\nb\nc\n",
+		},
+		{
+			title:   "Context changed",
+			b:       "1\n2\n3\n",
+			patch:   "@@ -1,2 +1,2 @@\n-one\n+uno\n 2\n",
+			wantErr: "hunk 1 at line 1 doesn't apply",
+		},
+		{
+			title:   "Truncated",
+			b:       "1\n2\n3\n",
+			patch:   "@@ -1,3 +1,3 @@\n-1\n+one\n",
+			wantErr: "shorter than its header",
+		},
+		{
+			title:   "Two files",
+			b:       "1\n",
+			patch:   "--- a\n+++ a\n@@ -1 +1 @@\n-1\n+2\n--- b\n+++ b\n@@ -1 +1 @@\n-1\n+2\n",
+			wantErr: "more than one file",
+		},
+		{
+			title:   "No hunks",
+			b:       "1\n",
+			patch:   "--- a\n+++ b\n",
+			wantErr: "no hunks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got, err := Apply([]byte(tt.b), []byte(tt.patch))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got = %v, want = %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got = %q, want = %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package merge
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// hunk is a hunk of a unified diff.
+type hunk struct {
+	// 1-based line of the old lines in the header, or the line they follow
+	// when there are none.
+	oldLine  int
+	old, new []string
+}
+
+// Apply applies the unified diff patch, as written by Unified or 'diff -u',
+// to b. A hunk is applied where its old lines are found nearest to the line
+// in its header, so patches keep applying when lines were added above them.
+// A hunk whose old lines aren't found is an error.
+func Apply(b, patch []byte) ([]byte, error) {
+	hunks, err := parse(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	la := lines(b)
+	var out []string
+	// Lines of la before pos are copied, and hunks moved by offset so far.
+	pos, offset := 0, 0
+	for i, h := range hunks {
+		want := h.oldLine + offset
+		if len(h.old) > 0 {
+			want--
+		}
+
+		at := find(la, h.old, want, pos)
+		if at < 0 {
+			return nil, fmt.Errorf("hunk %d at line %d doesn't apply", i+1, h.oldLine)
+		}
+
+		out = append(out, la[pos:at]...)
+		out = append(out, h.new...)
+		pos = at + len(h.old)
+		offset = at - want + offset
+	}
+	out = append(out, la[pos:]...)
+
+	return []byte(strings.Join(out, "")), nil
+}
+
+// find returns the index of old in ls at or after from that is nearest to
+// want, or -1.
+func find(ls []string, old []string, want int, from int) int {
+	last := len(ls) - len(old)
+	for d := 0; want-d >= from || want+d <= last; d++ {
+		for _, i := range []int{want - d, want + d} {
+			if i >= from && i <= last && equal(ls[i:i+len(old)], old) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parse returns the hunks of the unified diff of a single file.
+func parse(patch []byte) ([]hunk, error) {
+	var hunks []hunk
+	var h *hunk
+	// Lines the current hunk still has, from its header.
+	var oldLeft, newLeft int
+	// Lists the previous line was added to, for '\ No newline at end of file'.
+	var last []*[]string
+
+	for n, l := range lines(patch) {
+		switch {
+		case h != nil && (oldLeft > 0 || newLeft > 0):
+			kind, text := l[0], l[1:]
+			// Some tools write empty context lines without the space.
+			if l == "\n" {
+				kind, text = ' ', "\n"
+			}
+
+			switch kind {
+			case ' ':
+				h.old, h.new = append(h.old, text), append(h.new, text)
+				oldLeft, newLeft = oldLeft-1, newLeft-1
+				last = []*[]string{&h.old, &h.new}
+			case '-':
+				h.old = append(h.old, text)
+				oldLeft--
+				last = []*[]string{&h.old}
+			case '+':
+				h.new = append(h.new, text)
+				newLeft--
+				last = []*[]string{&h.new}
+			case '\\':
+				trimNewline(last)
+				continue
+			default:
+				return nil, fmt.Errorf("line %d: hunk ends early", n+1)
+			}
+			if oldLeft < 0 || newLeft < 0 {
+				return nil, fmt.Errorf("line %d: hunk is longer than its header", n+1)
+			}
+
+		case strings.HasPrefix(l, `\`):
+			trimNewline(last)
+
+		case strings.HasPrefix(l, "@@ "):
+			var oldLen, newLen int
+			var err error
+			hunks = append(hunks, hunk{})
+			h = &hunks[len(hunks)-1]
+			h.oldLine, oldLen, newLen, err = parseHeader(l)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			oldLeft, newLeft = oldLen, newLen
+			last = nil
+
+		case strings.HasPrefix(l, "--- ") && h != nil:
+			return nil, fmt.Errorf("line %d: patch changes more than one file", n+1)
+
+		default:
+			// Headers, e.g. 'diff', 'index', '---' and '+++' lines.
+		}
+	}
+
+	if oldLeft > 0 || newLeft > 0 {
+		return nil, errors.New("last hunk is shorter than its header")
+	}
+	if len(hunks) == 0 {
+		return nil, errors.New("patch has no hunks")
+	}
+	return hunks, nil
+}
+
+// parseHeader parses a hunk header, e.g. '@@ -2,7 +2,8 @@'.
+func parseHeader(l string) (oldLine, oldLen, newLen int, err error) {
+	fields := strings.Fields(l)
+	if len(fields) < 4 || fields[3] != "@@" || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q", strings.TrimSpace(l))
+	}
+
+	oldLine, oldLen, err = parseRange(fields[1][1:])
+	if err == nil {
+		_, newLen, err = parseRange(fields[2][1:])
+	}
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q", strings.TrimSpace(l))
+	}
+	return oldLine, oldLen, newLen, nil
+}
+
+// parseRange parses the 'line,length' of a hunk header. The length is 1 when
+// it is left out.
+func parseRange(s string) (int, int, error) {
+	line, length, ok := strings.Cut(s, ",")
+	if !ok {
+		length = "1"
+	}
+
+	l, err := strconv.Atoi(line)
+	if err != nil {
+		return 0, 0, err
+	}
+	n, err := strconv.Atoi(length)
+	if err != nil {
+		return 0, 0, err
+	}
+	return l, n, nil
+}
+
+func trimNewline(lists []*[]string) {
+	for _, ls := range lists {
+		if i := len(*ls) - 1; i >= 0 {
+			(*ls)[i] = strings.TrimSuffix((*ls)[i], "\n")
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package merge
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// contextLines is the number of unchanged lines around each hunk of a
+// unified diff.
+const contextLines = 3
+
+// op is a line of an edit script: ' ' kept, '-' deleted or '+' inserted.
+type op struct {
+	kind byte
+	line string
+}
+
+// Unified returns the unified diff from a to b, with oldName and newName in
+// its header, or nil when they are equal.
+func Unified(oldName, newName string, a, b []byte) []byte {
+	la, lb := lines(a), lines(b)
+	ops := script(la, lb)
+
+	var out bytes.Buffer
+	for start := 0; start < len(ops); {
+		// Find the next change.
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk while changes are at most 2*contextLines lines apart.
+		end := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i + 1
+			} else if i-end >= 2*contextLines {
+				break
+			}
+		}
+
+		from, to := max(start-contextLines, 0), min(end+contextLines, len(ops))
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		writeHunk(&out, ops, from, to)
+		start = to
+	}
+
+	if out.Len() == 0 {
+		return nil
+	}
+	return out.Bytes()
+}
+
+func writeHunk(out *bytes.Buffer, ops []op, from, to int) {
+	// Line numbers are 1-based and count the lines before the hunk.
+	oldLine, newLine := 1, 1
+	for _, o := range ops[:from] {
+		if o.kind != '+' {
+			oldLine++
+		}
+		if o.kind != '-' {
+			newLine++
+		}
+	}
+
+	oldLen, newLen := 0, 0
+	for _, o := range ops[from:to] {
+		if o.kind != '+' {
+			oldLen++
+		}
+		if o.kind != '-' {
+			newLen++
+		}
+	}
+	if oldLen == 0 {
+		oldLine--
+	}
+	if newLen == 0 {
+		newLine--
+	}
+
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", oldLine, oldLen, newLine, newLen)
+	for _, o := range ops[from:to] {
+		out.WriteByte(o.kind)
+		out.WriteString(o.line)
+		if len(o.line) == 0 || o.line[len(o.line)-1] != '\n' {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// script returns the edit script turning a into b.
+func script(a, b []string) []op {
+	m := matches(a, b)
+
+	var ops []op
+	j := 0
+	for i, l := range a {
+		if m[i] < 0 {
+			ops = append(ops, op{'-', l})
+			continue
+		}
+		for ; j < m[i]; j++ {
+			ops = append(ops, op{'+', b[j]})
+		}
+		ops = append(ops, op{' ', l})
+		j++
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+	return ops
+}
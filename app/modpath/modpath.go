@@ -0,0 +1,210 @@
+// Package modpath validates Go module paths using the same rules as
+// 'go mod init' and the module proxy, so that an invalid path is reported
+// before any files are written.
+package modpath
+
+import (
+	"errors"
+	"fmt"
+	"go/build"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error describes why a module path is invalid. Element and Index identify
+// the offending path element, Index is -1 when the error concerns the path as
+// a whole.
+type Error struct {
+	Path    string
+	Element string
+	Index   int
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("create-go-app: invalid module path '%s': %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("create-go-app: invalid module path '%s': element %d '%s': %v", e.Path, e.Index+1, e.Element, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Windows disallows these names as a file name, with or without an extension.
+var badWindowsNames = []string{
+	"CON", "PRN", "AUX", "NUL",
+	"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+}
+
+// Module paths the go command reserves for itself.
+var reservedPaths = []string{"std", "cmd", "all", "tool", "work"}
+
+// Check reports whether path is a valid module path. The returned error is
+// an *Error.
+func Check(path string) error {
+	pathErr := func(format string, args ...any) error {
+		return &Error{Path: path, Index: -1, Err: fmt.Errorf(format, args...)}
+	}
+
+	if path == "" {
+		return pathErr("empty string")
+	}
+	if !utf8.ValidString(path) {
+		return pathErr("invalid UTF-8")
+	}
+	if strings.HasPrefix(path, "/") {
+		return pathErr("leading slash, module paths are not file paths")
+	}
+	if path == "." || path == ".." || strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") {
+		return pathErr("is a local import path")
+	}
+	if strings.HasSuffix(path, "/") {
+		return pathErr("trailing slash")
+	}
+	if strings.Contains(path, "//") {
+		return pathErr("double slash")
+	}
+
+	elems := strings.Split(path, "/")
+	for i, elem := range elems {
+		if err := checkElem(elem); err != nil {
+			return &Error{Path: path, Element: elem, Index: i, Err: err}
+		}
+	}
+
+	first := elems[0]
+	firstErr := func(format string, args ...any) error {
+		return &Error{Path: path, Element: first, Index: 0, Err: fmt.Errorf(format, args...)}
+	}
+
+	if strings.HasPrefix(first, "-") {
+		return firstErr("leading dash in first path element")
+	}
+
+	if strings.Contains(first, ".") {
+		// The first element is a domain name. Only lower case is allowed so
+		// that paths are the same on case-insensitive file systems.
+		for _, r := range first {
+			if !('a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '-' || r == '.') {
+				return firstErr("invalid char '%c' in first path element, only lower case letters, digits, '-' and '.' are allowed", r)
+			}
+		}
+	} else {
+		for _, reserved := range reservedPaths {
+			if path == reserved {
+				return firstErr("'%s' is reserved by the go command", reserved)
+			}
+		}
+		if isStdPackage(first) {
+			return firstErr("'%s' is a standard library package, use a path with a dot in the first element, e.g. 'example.com/%s'", first, path)
+		}
+	}
+
+	if err := checkMajorVersion(elems); err != nil {
+		last := len(elems) - 1
+		return &Error{Path: path, Element: elems[last], Index: last, Err: err}
+	}
+
+	return nil
+}
+
+func checkElem(elem string) error {
+	if elem == "" {
+		return errors.New("empty path element")
+	}
+	if strings.Count(elem, ".") == len(elem) {
+		return errors.New("path element must not be only dots")
+	}
+	if elem[len(elem)-1] == '.' {
+		return errors.New("trailing dot in path element")
+	}
+
+	for _, r := range elem {
+		if !importPathOK(r) {
+			return fmt.Errorf("invalid char '%c', only ASCII letters, digits and '-', '.', '_', '~', '+' are allowed", r)
+		}
+	}
+
+	short := elem
+	if i := strings.Index(short, "."); i >= 0 {
+		short = short[:i]
+	}
+
+	for _, bad := range badWindowsNames {
+		if strings.EqualFold(bad, short) {
+			return fmt.Errorf("'%s' is a reserved file name on Windows", short)
+		}
+	}
+
+	// Names like 'EXAMPL~1' look like Windows short names.
+	if tilde := strings.LastIndexByte(short, '~'); tilde >= 0 && tilde < len(short)-1 {
+		if isDigits(short[tilde+1:]) {
+			return errors.New("trailing tilde and digits in path element")
+		}
+	}
+
+	return nil
+}
+
+// checkMajorVersion validates a trailing major version suffix, '/vN' or
+// '.vN' for gopkg.in paths.
+func checkMajorVersion(elems []string) error {
+	last := elems[len(elems)-1]
+
+	if elems[0] == "gopkg.in" {
+		i := strings.LastIndex(last, ".v")
+		if len(elems) < 2 || i < 0 || !isMajor(last[i+2:], true) {
+			return errors.New("gopkg.in paths must end in a major version suffix of the form '.vN', e.g. 'gopkg.in/yaml.v3'")
+		}
+		return nil
+	}
+
+	if len(elems) < 2 || len(last) < 2 || last[0] != 'v' || !isDigits(last[1:]) {
+		return nil
+	}
+
+	if !isMajor(last[1:], false) {
+		return fmt.Errorf("major version suffixes must be of the form '/vN' and are only allowed for v2 or later, got '/%s'", last)
+	}
+
+	return nil
+}
+
+func isMajor(n string, allowV0V1 bool) bool {
+	if !isDigits(n) || (len(n) > 1 && n[0] == '0') {
+		return false
+	}
+	if allowV0V1 {
+		return true
+	}
+	return n != "0" && n != "1"
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func importPathOK(r rune) bool {
+	return 'a' <= r && r <= 'z' ||
+		'A' <= r && r <= 'Z' ||
+		'0' <= r && r <= '9' ||
+		r == '-' || r == '.' || r == '_' || r == '~' || r == '+'
+}
+
+// isStdPackage reports whether name is a package in the standard library of
+// the installed Go toolchain.
+func isStdPackage(name string) bool {
+	pkg, err := build.Default.Import(name, "", build.FindOnly)
+	return err == nil && pkg.Goroot
+}
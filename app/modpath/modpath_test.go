@@ -0,0 +1,61 @@
+package modpath
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		title     string
+		path      string
+		wantErr   bool
+		wantIndex int
+	}{
+		{title: "Domain path", path: "github.com/username/my-app"},
+		{title: "Mixed case after the domain", path: "github.com/UserName/MyApp"},
+		{title: "No dot in first element", path: "myapp"},
+		{title: "Major version suffix", path: "example.com/app/v2"},
+		{title: "gopkg.in suffix", path: "gopkg.in/yaml.v3"},
+		{title: "Allowed punctuation", path: "example.com/a_b~c+d.e-f"},
+		{title: "Empty", path: "", wantErr: true, wantIndex: -1},
+		{title: "Leading slash", path: "/example.com/app", wantErr: true, wantIndex: -1},
+		{title: "Local path", path: "./app", wantErr: true, wantIndex: -1},
+		{title: "Trailing slash", path: "example.com/app/", wantErr: true, wantIndex: -1},
+		{title: "Double slash", path: "example.com//app", wantErr: true, wantIndex: -1},
+		{title: "Invalid char", path: "example.com/my app", wantErr: true, wantIndex: 1},
+		{title: "Only dots", path: "example.com/../app", wantErr: true, wantIndex: 1},
+		{title: "Trailing dot", path: "example.com/app.", wantErr: true, wantIndex: 1},
+		{title: "Windows reserved name", path: "example.com/con.d/app", wantErr: true, wantIndex: 1},
+		{title: "Windows short name", path: "example.com/EXAMPL~1", wantErr: true, wantIndex: 1},
+		{title: "Upper case domain", path: "GitHub.com/username/app", wantErr: true, wantIndex: 0},
+		{title: "Leading dash", path: "-example.com/app", wantErr: true, wantIndex: 0},
+		{title: "Reserved by the go command", path: "std", wantErr: true, wantIndex: 0},
+		{title: "Standard library package", path: "fmt/app", wantErr: true, wantIndex: 0},
+		{title: "v1 suffix", path: "example.com/app/v1", wantErr: true, wantIndex: 2},
+		{title: "Leading zero suffix", path: "example.com/app/v02", wantErr: true, wantIndex: 2},
+		{title: "gopkg.in without suffix", path: "gopkg.in/yaml", wantErr: true, wantIndex: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			err := Check(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got = %v, want = %v", err, tt.wantErr)
+			}
+
+			if err == nil {
+				return
+			}
+
+			var pathErr *Error
+			if !errors.As(err, &pathErr) {
+				t.Fatalf("got = %T, want = *Error", err)
+			}
+
+			if pathErr.Index != tt.wantIndex {
+				t.Errorf("index = %d, want = %d (%v)", pathErr.Index, tt.wantIndex, err)
+			}
+		})
+	}
+}
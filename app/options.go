@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"create-go-app.dev/license"
+	"create-go-app.dev/modpath"
+	"create-go-app.dev/templates"
+	"create-go-app.dev/tmpl"
+	"create-go-app.dev/wizard"
+)
+
+// askOptions runs the wizard when the app runs interactively and the module
+// path or the components aren't given, the inputs the user was always asked
+// for. The wizard then asks for every option not set with a flag, the type
+// only without -template, whose type is tplType, and sets the flags to the
+// answers. The configured defaults are preselected. It returns the ports the
+// app publishes.
+func askOptions(ctx context.Context, appName string, tpl *templates.Template, tplType projectType) (tmpl.Ports, error) {
+	ports := tmpl.DefaultPorts()
+	ports.Go = *portFlag
+
+	given := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "type":
+			given[wizard.StepType] = true
+		case "with", "without":
+			given[wizard.StepComponents] = true
+			given[wizard.StepDatabase] = true
+		case "port":
+			given[wizard.StepPorts] = true
+		case "license":
+			given[wizard.StepLicense] = true
+		}
+	})
+
+	// A module path from the module prefix is only a default.
+	given[wizard.StepModule] = strings.TrimSpace(*moduleFlag) != "" || strings.TrimSpace(os.Getenv("CREATE_GO_APP_MODULE")) != ""
+	module, _ := resolveModuleName(*moduleFlag, appName)
+
+	if *yesFlag || (given[wizard.StepModule] && given[wizard.StepComponents]) {
+		return ports, nil
+	}
+
+	var types []wizard.Type
+	if tpl != nil {
+		given[wizard.StepType] = true
+		types = append(types, wizard.Type{Name: tpl.Name, Summary: tpl.Description, Components: tplType.components, Ports: tplType.ports})
+	} else {
+		for _, name := range slices.Sorted(maps.Keys(projectTypes)) {
+			pt := projectTypes[name]
+			types = append(types, wizard.Type{Name: name, Summary: pt.summary, Components: pt.components, Ports: pt.ports})
+		}
+	}
+
+	a := &wizard.Answers{
+		Type:    *strFlag,
+		Module:  module,
+		Ports:   ports,
+		License: *licenseFlag,
+	}
+	if tpl != nil {
+		a.Type = tpl.Name
+	}
+
+	// The components given or the default ones.
+	i := slices.IndexFunc(types, func(t wizard.Type) bool { return t.Name == a.Type })
+	var err error
+	a.Components, err = selectComponents(types[i].Components)
+	if err != nil {
+		return tmpl.Ports{}, err
+	}
+
+	w := wizard.New(os.Stdin, os.Stdout)
+	w.Types = types
+	w.Licenses = license.Names
+	w.Given = given
+	w.CheckModule = modpath.Check
+
+	err = w.Run(ctx, a)
+	if err != nil {
+		return tmpl.Ports{}, err
+	}
+
+	with := strings.Join(a.Components, ",")
+	if with == "" {
+		with = "none"
+	}
+
+	// Only the flags the wizard asked for are set, so -with and -without
+	// aren't mixed.
+	for _, f := range []struct{ step, name, value string }{
+		{wizard.StepType, "type", a.Type},
+		{wizard.StepModule, "module", a.Module},
+		{wizard.StepComponents, "with", with},
+		{wizard.StepLicense, "license", a.License},
+	} {
+		if given[f.step] {
+			continue
+		}
+		err = flag.Set(f.name, f.value)
+		if err != nil {
+			return tmpl.Ports{}, err
+		}
+	}
+
+	return a.Ports, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"create-go-app.dev/fsys"
+)
+
+// checkArchiveName returns an error unless name is a -output archive
+// create-go-app can write.
+func checkArchiveName(name string) error {
+	for _, suffix := range []string{".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(name, suffix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("create-go-app: invalid -output '%s', expected a .tar.gz, .tgz or .zip file", name)
+}
+
+// writeArchive writes the app generated in dir to the archive file name,
+// with every entry under the directory prefix and modified at modTime. The
+// archive is written next to name, whose missing parents are created, and
+// renamed once complete, so name is either the whole archive or left as it
+// was.
+func writeArchive(dir string, name string, prefix string, modTime time.Time) error {
+	err := checkArchiveName(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(name), fsys.DirPerm)
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(filepath.Dir(name), "."+filepath.Base(name)+".tmp-"+strconv.Itoa(os.Getpid()))
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fsys.FilePerm)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	defer f.Close()
+
+	var a *fsys.Archive
+	var gz *gzip.Writer
+	if strings.HasSuffix(name, ".zip") {
+		a = fsys.NewZip(f, prefix)
+	} else {
+		gz = gzip.NewWriter(f)
+		a = fsys.NewTar(gz, prefix)
+	}
+	a.ModTime = modTime
+
+	err = fsys.Copy(a, fsys.OS(dir))
+	if err != nil {
+		return err
+	}
+
+	err = a.Close()
+	if err != nil {
+		return err
+	}
+
+	if gz != nil {
+		err = gz.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, name)
+}
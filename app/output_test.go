@@ -0,0 +1,166 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckArchiveName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "my-app.tar.gz"},
+		{name: "dist/my-app.tgz"},
+		{name: "my-app.zip"},
+		{name: "my-app.tar", wantErr: true},
+		{name: "my-app", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := checkArchiveName(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkArchiveName(%q) = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWriteArchive(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		title string
+		name  string
+	}{
+		{title: "tar.gz", name: "my-app.tar.gz"},
+		{title: "tgz in a missing directory", name: "dist/release/my-app.tgz"},
+		{title: "zip", name: "my-app.zip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "app")
+			writeFiles(t, dir, map[string]string{"go/go.mod": "module my-app\n", "README.md": "# my-app\n"})
+			out := t.TempDir()
+			name := filepath.Join(out, filepath.FromSlash(tt.name))
+
+			err := writeArchive(dir, name, "my-app", modTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := archivedFiles(t, name)
+			want := map[string]string{"my-app/go/go.mod": "module my-app\n", "my-app/README.md": "# my-app\n"}
+			if !maps.Equal(got, want) {
+				t.Errorf("archived %q, want %q", got, want)
+			}
+
+			// Only the archive is left, not the temporary file it was written to.
+			entries, err := os.ReadDir(filepath.Dir(name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("%d files next to the archive, want 1", len(entries))
+			}
+		})
+	}
+}
+
+func TestWriteArchiveFailure(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "my-app.zip")
+	err := os.WriteFile(name, []byte("previous"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = writeArchive(filepath.Join(t.TempDir(), "missing"), name, "my-app", time.Now())
+	if err == nil {
+		t.Fatal("no error for a missing app directory")
+	}
+
+	// The previous archive is left as it was, without the partial one.
+	b, err := os.ReadFile(name)
+	if err != nil || string(b) != "previous" {
+		t.Errorf("archive = %q, %v, want the previous one", b, err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("%d files next to the archive, want 1", len(entries))
+	}
+}
+
+// archivedFiles returns the contents of the regular files in the tar.gz,
+// tgz or zip archive name, by entry name.
+func archivedFiles(t *testing.T, name string) map[string]string {
+	t.Helper()
+
+	files := map[string]string{}
+
+	if filepath.Ext(name) == ".zip" {
+		zr, err := zip.OpenReader(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer zr.Close()
+
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			r, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := io.ReadAll(r)
+			r.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			files[f.Name] = string(b)
+		}
+		return files
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = string(b)
+	}
+	return files
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"create-go-app.dev/fsys"
+	"create-go-app.dev/overlay"
+	"create-go-app.dev/templates"
+)
+
+// overlayDirs is the value of the repeatable '-overlay <dir>' flag.
+type overlayDirs []string
+
+func (o *overlayDirs) String() string {
+	return strings.Join(*o, ",")
+}
+
+func (o *overlayDirs) Set(dir string) error {
+	*o = append(*o, dir)
+	return nil
+}
+
+// applyOverlays stacks the overlay directories dirs, in order, over the
+// templates in src of the type pt. It also returns the directories'
+// absolute paths for the manifest.
+func applyOverlays(src fs.FS, pt projectType, dirs []string) (*overlay.Stack, []string, error) {
+	var layers []overlay.Layer
+	var abs []string
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			return nil, nil, fmt.Errorf("create-go-app: overlay '%s' isn't a directory", dir)
+		}
+
+		path, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		layers = append(layers, overlay.Layer{Name: "overlay " + dir, FS: os.DirFS(dir)})
+		abs = append(abs, path)
+	}
+
+	s, err := overlay.Apply(src, pt.embedPath, layers)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, abs, nil
+}
+
+// baseLayer returns the name -explain gives the layer of the templates that
+// files no overlay added come from: the embedded type, or the user-supplied
+// template tpl when it has no base type.
+func baseLayer(typeName string, tpl *templates.Template) string {
+	switch {
+	case tpl == nil:
+		return "embedded " + typeName
+	case tpl.Base == "":
+		return "template " + tpl.Name
+	}
+	return "embedded " + tpl.Base
+}
+
+// explain prints the layer each file generated from s for the selected
+// components comes from, followed by the files the layers deleted.
+func explain(w io.Writer, s *overlay.Stack, pt projectType, components []string, licenseName string, base string) error {
+	fsys.EmbedPath = pt.embedPath
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	err := fs.WalkDir(s, pt.embedPath, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if rel, _ := filepath.Rel(pt.embedPath, name); skipped(pt, filepath.ToSlash(rel), components, licenseName) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		o := s.Origins[name]
+		from := base
+		if o.Layer != "" {
+			from = o.Layer
+		}
+		if len(o.Patches) > 0 {
+			from += ", patched by " + strings.Join(o.Patches, ", ")
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\n", fsys.Name(name), from)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(s.Deleted)) {
+		fmt.Fprintf(tw, "%s\tdeleted by %s\n", fsys.Name(name), s.Deleted[name])
+	}
+
+	return tw.Flush()
+}
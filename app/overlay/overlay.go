@@ -0,0 +1,220 @@
+// Package overlay stacks overlay directories, e.g. an organization's LICENSE
+// header, CODEOWNERS and Dockerfile changes, over the templates a project is
+// generated from, and records which layer each file comes from.
+package overlay
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"create-go-app.dev/fsys"
+	"create-go-app.dev/merge"
+	"create-go-app.dev/tmpl"
+)
+
+const (
+	// PatchSuffix marks a file of a layer as a unified diff applied to the
+	// file of the same name without the suffix below it.
+	PatchSuffix = ".patch"
+	// DeleteSuffix marks a file of a layer as removing the file or directory
+	// of the same name without the suffix below it. Its contents are ignored.
+	DeleteSuffix = ".delete"
+)
+
+// Layer is an overlay directory. Any other file replaces or adds the file of
+// the same name.
+type Layer struct {
+	// Name the layer is shown as, e.g. 'overlay ./org' or 'template
+	// platform'.
+	Name string
+	FS   fs.FS
+}
+
+// Origin is where a file of a stack comes from.
+type Origin struct {
+	// Layer that added or replaced the file, empty for the templates.
+	Layer string
+	// Layers that patched it, bottom up.
+	Patches []string
+}
+
+// Stack is templates with layers applied.
+type Stack struct {
+	*fsys.Mem
+	// Origins of the files, by path.
+	Origins map[string]Origin
+	// Layers that deleted files of the templates, by path.
+	Deleted map[string]string
+}
+
+// Apply returns the templates in src below root with layers applied in
+// order, each over the ones before it. Names in the layers are relative to
+// root. Within a layer files are applied in lexical order, so the result
+// doesn't depend on the order they are listed in. When src is a Stack with
+// the same root, the origins and deletions of its layers are kept.
+func Apply(src fs.FS, root string, layers []Layer) (*Stack, error) {
+	s := &Stack{
+		Mem:     fsys.NewMem(),
+		Origins: map[string]Origin{},
+		Deleted: map[string]string{},
+	}
+
+	lower, _ := src.(*Stack)
+	if lower != nil {
+		maps.Copy(s.Deleted, lower.Deleted)
+	}
+
+	err := fs.WalkDir(src, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return s.MkdirAll(name, fsys.DirPerm)
+		}
+
+		b, err := fs.ReadFile(src, name)
+		if err != nil {
+			return err
+		}
+		s.Origins[name] = Origin{}
+		if lower != nil {
+			s.Origins[name] = lower.Origins[name]
+		}
+		return s.WriteFile(name, b, fsys.Mode(name, b))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range layers {
+		err := fs.WalkDir(l.FS, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if name == "." {
+				return nil
+			}
+
+			err = s.apply(l, root, name, d.IsDir())
+			if err != nil {
+				return fmt.Errorf("create-go-app: %s: %s: %w", l.Name, name, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// apply applies the file or directory name of the layer l.
+func (s *Stack) apply(l Layer, root string, name string, isDir bool) error {
+	target := path.Join(root, name)
+	if isDir {
+		return s.MkdirAll(target, fsys.DirPerm)
+	}
+
+	var suffix string
+	for _, sfx := range []string{PatchSuffix, DeleteSuffix} {
+		if strings.HasSuffix(name, sfx) {
+			suffix = sfx
+			target = strings.TrimSuffix(target, sfx)
+		}
+	}
+
+	if suffix != "" {
+		// Replacing a file and changing it in the same layer is ambiguous.
+		if _, err := fs.Stat(l.FS, strings.TrimSuffix(name, suffix)); err == nil {
+			return errors.New("the layer also has the file it changes")
+		}
+
+		// Changing 'LICENSE' changes the template 'LICENSE.tmpl' it is
+		// generated from.
+		if _, err := fs.Stat(s, target); errors.Is(err, fs.ErrNotExist) {
+			if _, err := fs.Stat(s, target+tmpl.Suffix); err == nil {
+				target += tmpl.Suffix
+			}
+		}
+	}
+
+	info, err := fs.Stat(s, target)
+	if suffix != "" && errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s isn't in the layers below", path.Base(target))
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	switch suffix {
+	case DeleteSuffix:
+		err = s.RemoveAll(target)
+		if err != nil {
+			return err
+		}
+		for p := range s.Origins {
+			if p == target || strings.HasPrefix(p, target+"/") {
+				delete(s.Origins, p)
+			}
+		}
+		s.Deleted[target] = l.Name
+		return nil
+
+	case PatchSuffix:
+		if info.IsDir() {
+			return fmt.Errorf("%s is a directory", path.Base(target))
+		}
+
+		b, err := fs.ReadFile(s, target)
+		if err != nil {
+			return err
+		}
+		patch, err := fs.ReadFile(l.FS, name)
+		if err != nil {
+			return err
+		}
+
+		b, err = merge.Apply(b, patch)
+		if err != nil {
+			return err
+		}
+
+		o := s.Origins[target]
+		o.Patches = append(slices.Clip(o.Patches), l.Name)
+		s.Origins[target] = o
+		return s.WriteFile(target, b, fsys.Mode(target, b))
+	}
+
+	if err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a directory below", path.Base(target))
+	}
+
+	b, err := fs.ReadFile(l.FS, name)
+	if err != nil {
+		return err
+	}
+
+	// The file also replaces the one generating the same file, e.g.
+	// 'LICENSE' replaces 'LICENSE.tmpl' and the other way around.
+	twin := target + tmpl.Suffix
+	if strings.HasSuffix(target, tmpl.Suffix) {
+		twin = strings.TrimSuffix(target, tmpl.Suffix)
+	}
+	if info, err := fs.Stat(s, twin); err == nil && !info.IsDir() {
+		err = s.RemoveAll(twin)
+		if err != nil {
+			return err
+		}
+		delete(s.Origins, twin)
+	}
+
+	s.Origins[target] = Origin{Layer: l.Name}
+	delete(s.Deleted, target)
+	return s.WriteFile(target, b, fsys.Mode(target, b))
+}
@@ -0,0 +1,205 @@
+package overlay
+
+import (
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestApply(t *testing.T) {
+	src := fstest.MapFS{
+		"embed/LICENSE.tmpl":      {Data: []byte("MIT\n")},
+		"embed/Dockerfile":        {Data: []byte("FROM golang\nRUN go build\nCMD [\"app\"]\n")},
+		"embed/go/cmd/main.go":    {Data: []byte("package main\n")},
+		"embed/node/package.json": {Data: []byte("{}\n")},
+		"other/ignored.txt":       {Data: []byte("outside the root\n")},
+	}
+
+	org := Layer{Name: "org", FS: fstest.MapFS{
+		"LICENSE":                {Data: []byte("Copyright Org\n")},
+		"CODEOWNERS":             {Data: []byte("* @org/platform\n")},
+		"Dockerfile.patch":       {Data: []byte("--- a/Dockerfile\n+++ b/Dockerfile\n@@ -1,2 +1,3 @@\n FROM golang\n+ENV GOFLAGS=-mod=readonly\n RUN go build\n")},
+		"node.delete":            {},
+		"go/internal/log/log.go": {Data: []byte("package log\n")},
+	}}
+	team := Layer{Name: "team", FS: fstest.MapFS{
+		"Dockerfile.patch": {Data: []byte("@@ -4 +4 @@\n-CMD [\"app\"]\n+CMD [\"app\", \"-v\"]\n")},
+		"node/index.js":    {Data: []byte("// back\n")},
+	}}
+
+	s, err := Apply(src, "embed", []Layer{org, team})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fstest.TestFS(s, "embed/LICENSE", "embed/CODEOWNERS", "embed/Dockerfile", "embed/go/cmd/main.go", "embed/go/internal/log/log.go", "embed/node/index.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"embed/LICENSE":    "Copyright Org\n",
+		"embed/Dockerfile": "FROM golang\nENV GOFLAGS=-mod=readonly\nRUN go build\nCMD [\"app\", \"-v\"]\n",
+	}
+	for name, want := range files {
+		b, err := fs.ReadFile(s, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != want {
+			t.Errorf("%s = %q, want = %q", name, b, want)
+		}
+	}
+
+	for _, name := range []string{"embed/LICENSE.tmpl", "embed/node/package.json", "embed/Dockerfile.patch", "embed/node.delete", "other/ignored.txt"} {
+		if _, err := fs.Stat(s, name); err == nil {
+			t.Errorf("%s exists", name)
+		}
+	}
+
+	origins := map[string]string{
+		"embed/go/cmd/main.go": "",
+		"embed/LICENSE":        "org",
+		"embed/node/index.js":  "team",
+		"embed/Dockerfile":     " patched by org team",
+	}
+	for name, want := range origins {
+		o, ok := s.Origins[name]
+		if !ok {
+			t.Fatalf("no origin for %s", name)
+		}
+		got := o.Layer
+		if len(o.Patches) > 0 {
+			got += " patched by " + strings.Join(o.Patches, " ")
+		}
+		if got != want {
+			t.Errorf("%s = %q, want = %q", name, got, want)
+		}
+	}
+	if _, ok := s.Origins["embed/node/package.json"]; ok {
+		t.Errorf("deleted file has an origin")
+	}
+	if s.Deleted["embed/node"] != "org" {
+		t.Errorf("deleted = %v", s.Deleted)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	src := fstest.MapFS{
+		"Dockerfile": {Data: []byte("FROM golang\n")},
+		"go/main.go": {Data: []byte("package main\n")},
+	}
+
+	tests := []struct {
+		title string
+		layer fstest.MapFS
+		want  string
+	}{
+		{
+			title: "Patch missing file",
+			layer: fstest.MapFS{"Makefile.patch": {Data: []byte("@@ -1 +1 @@\n-a\n+b\n")}},
+			want:  "overlay bad: Makefile.patch: Makefile isn't in the layers below",
+		},
+		{
+			title: "Delete missing file",
+			layer: fstest.MapFS{"Makefile.delete": {}},
+			want:  "Makefile isn't in the layers below",
+		},
+		{
+			title: "Patch doesn't apply",
+			layer: fstest.MapFS{"Dockerfile.patch": {Data: []byte("@@ -1 +1 @@\n-FROM alpine\n+FROM scratch\n")}},
+			want:  "hunk 1 at line 1 doesn't apply",
+		},
+		{
+			title: "Replaced and patched",
+			layer: fstest.MapFS{
+				"Dockerfile":       {Data: []byte("FROM scratch\n")},
+				"Dockerfile.patch": {Data: []byte("@@ -1 +1 @@\n-FROM golang\n+FROM scratch\n")},
+			},
+			want: "also has the file it changes",
+		},
+		{
+			title: "File over a directory",
+			layer: fstest.MapFS{"go": {Data: []byte("not a directory\n")}},
+			want:  "go is a directory below",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			_, err := Apply(src, ".", []Layer{{Name: "overlay bad", FS: tt.layer}})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got = %v, want = %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyOrder(t *testing.T) {
+	src := fstest.MapFS{"README.md": {Data: []byte("base\n")}}
+
+	// The last layer wins.
+	var got []string
+	for _, names := range [][]string{{"a", "b"}, {"b", "a"}} {
+		var layers []Layer
+		for _, name := range names {
+			layers = append(layers, Layer{Name: name, FS: fstest.MapFS{"README.md": {Data: []byte(name + "\n")}}})
+		}
+
+		s, err := Apply(src, ".", layers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := fs.ReadFile(s, "README.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(b))
+	}
+
+	if !slices.Equal(got, []string{"b\n", "a\n"}) {
+		t.Errorf("got = %q", got)
+	}
+}
+
+func TestApplyStack(t *testing.T) {
+	src := fstest.MapFS{
+		"Dockerfile":   {Data: []byte("FROM golang\n")},
+		"swagger.yaml": {Data: []byte("openapi: 3.0.0\n")},
+		"README.md":    {Data: []byte("base\n")},
+	}
+
+	// A template over the embedded files, then an overlay over both.
+	tpl, err := Apply(src, ".", []Layer{{Name: "template platform", FS: fstest.MapFS{
+		"README.md":           {Data: []byte("platform\n")},
+		"swagger.yaml.delete": {},
+		"Dockerfile.patch":    {Data: []byte("@@ -1 +1 @@\n-FROM golang\n+FROM golang:1.23\n")},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Apply(tpl, ".", []Layer{{Name: "overlay org", FS: fstest.MapFS{
+		"CODEOWNERS": {Data: []byte("* @org\n")},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]Origin{
+		"CODEOWNERS": {Layer: "overlay org"},
+		"Dockerfile": {Patches: []string{"template platform"}},
+		"README.md":  {Layer: "template platform"},
+	}
+	for name, o := range want {
+		got := s.Origins[name]
+		if got.Layer != o.Layer || !slices.Equal(got.Patches, o.Patches) {
+			t.Errorf("%s from %+v, want %+v", name, got, o)
+		}
+	}
+	if s.Deleted["swagger.yaml"] != "template platform" {
+		t.Errorf("deleted = %v", s.Deleted)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, filepath.Join(dir, "org"), map[string]string{
+		"CODEOWNERS":           "* @org/platform\n",
+		".dockerignore.delete": "",
+	})
+
+	cmd := exec.Command(os.Args[0], "-explain", "-yes", "-module", "example.com/my-app", "-overlay", "./org", "my-app")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CREATE_GO_APP_TEST_HELPER=main", "XDG_CONFIG_HOME="+filepath.Join(dir, "config"))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+
+	for _, want := range []string{
+		`(?m)^CODEOWNERS +overlay \./org$`,
+		`(?m)^go/go\.mod +embedded `,
+		`(?m)^\.dockerignore +deleted by overlay \./org$`,
+	} {
+		if !regexp.MustCompile(want).Match(out) {
+			t.Errorf("output doesn't match %s:\n%s", want, out)
+		}
+	}
+
+	// Only the listing, nothing was generated.
+	if strings.Contains(string(out), "completed successfully") {
+		t.Errorf("output reports generating the app:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "my-app")); !os.IsNotExist(err) {
+		t.Errorf("my-app was written: %v", err)
+	}
+}
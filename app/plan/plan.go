@@ -0,0 +1,283 @@
+// Package plan renders a project entirely in memory to describe what
+// generating it would write and run, without touching the disk.
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"create-go-app.dev/fsys"
+	"create-go-app.dev/tmpl"
+)
+
+// Entry is a directory or file that would be written.
+type Entry struct {
+	// Slash separated path, starting with the app's root directory name.
+	Path string `json:"path"`
+	// Embedded path the entry is generated from, empty for the files the
+	// generator writes itself, e.g. the manifest.
+	Source  string              `json:"source"`
+	Dir     bool                `json:"dir"`
+	Size    int                 `json:"size"`
+	Mode    Mode                `json:"mode"`
+	Imports []fsys.ImportChange `json:"imports,omitempty"`
+}
+
+// Mode is a file mode that is encoded in JSON as an octal string.
+type Mode os.FileMode
+
+func (m Mode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("%04o", os.FileMode(m).Perm()))
+}
+
+// Step is a command that would be run before or after the files are written.
+type Step struct {
+	// Directory the command runs in.
+	Dir  string   `json:"dir"`
+	Args []string `json:"args"`
+}
+
+// Plan is everything generating a project would do.
+type Plan struct {
+	Root    string  `json:"root"`
+	Module  string  `json:"module"`
+	Entries []Entry `json:"entries"`
+	// Commands run before and after the files are written.
+	PreSteps []Step `json:"preSteps,omitempty"`
+	Steps    []Step `json:"steps"`
+}
+
+// Options control how a plan is built.
+type Options struct {
+	// The app's root directory name.
+	Name string
+	// Embedded directory the project is generated from, e.g. 'embed'.
+	EmbedPath string
+	// Placeholder module path in the embedded Go files and the user's
+	// module path that replaces it.
+	OldModule string
+	NewModule string
+	Data      tmpl.Data
+	// Skip reports whether a slash separated path relative to EmbedPath is
+	// left out, e.g. because its component wasn't selected.
+	Skip func(rel string) bool
+}
+
+// Build walks the embedded directory exactly like generating the project
+// does, rendering templates and rewriting imports in memory.
+func Build(src fs.FS, opts Options) (*Plan, error) {
+	p := &Plan{
+		Root:   filepath.ToSlash(opts.Name),
+		Module: opts.NewModule,
+	}
+
+	out := fsys.NewMem()
+
+	err := fs.WalkDir(src, opts.EmbedPath, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// A template directory is walked from ".", whose files have no prefix.
+		rel := strings.TrimPrefix(name, opts.EmbedPath+"/")
+		if name == opts.EmbedPath {
+			rel = ""
+		}
+		if rel != "" && opts.Skip != nil && opts.Skip(rel) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		err = fsys.Output(out, name, d.IsDir(), src, opts.Data)
+		if err != nil {
+			return err
+		}
+
+		info, err := fs.Stat(out, fsys.Name(name))
+		if err != nil {
+			return err
+		}
+
+		entry := Entry{
+			Path:   filepath.ToSlash(fsys.Destination(opts.Name, name)),
+			Source: name,
+			Dir:    d.IsDir(),
+			Mode:   Mode(info.Mode().Perm()),
+		}
+
+		if d.IsDir() {
+			p.Entries = append(p.Entries, entry)
+			return nil
+		}
+
+		b, err := fs.ReadFile(out, fsys.Name(name))
+		if err != nil {
+			return err
+		}
+
+		if path.Ext(entry.Path) == ".go" {
+			b, entry.Imports, err = fsys.ImportChanges(entry.Path, b, opts.OldModule, opts.NewModule)
+			if err != nil {
+				return err
+			}
+		}
+
+		entry.Size = len(b)
+		p.Entries = append(p.Entries, entry)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(p.Entries, func(a, b Entry) int {
+		return comparePaths(a.Path, b.Path)
+	})
+
+	return p, nil
+}
+
+// AddFile records a file the generator writes itself at the slash separated
+// path rel, relative to the app's root directory, along with its missing
+// parent directories.
+func (p *Plan) AddFile(rel string, size int, mode os.FileMode) {
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		name := path.Join(p.Root, dir)
+		if !slices.ContainsFunc(p.Entries, func(e Entry) bool { return e.Path == name }) {
+			p.Entries = append(p.Entries, Entry{Path: name, Dir: true, Mode: Mode(fsys.DirPerm)})
+		}
+	}
+	p.Entries = append(p.Entries, Entry{Path: path.Join(p.Root, rel), Size: size, Mode: Mode(mode)})
+
+	slices.SortFunc(p.Entries, func(a, b Entry) int {
+		return comparePaths(a.Path, b.Path)
+	})
+}
+
+// AddStep records a command run in dir, relative to the working directory.
+func (p *Plan) AddStep(dir string, args ...string) {
+	p.Steps = append(p.Steps, Step{Dir: filepath.ToSlash(dir), Args: args})
+}
+
+// AddPreStep records a command run in dir, relative to the working
+// directory, before the files are written.
+func (p *Plan) AddPreStep(dir string, args ...string) {
+	p.PreSteps = append(p.PreSteps, Step{Dir: filepath.ToSlash(dir), Args: args})
+}
+
+// WriteJSON writes the plan as indented JSON.
+func (p *Plan) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+// WriteTree writes the plan as a file tree between the steps run before and
+// after it.
+func (p *Plan) WriteTree(w io.Writer) error {
+	var files, size int
+
+	if len(p.PreSteps) > 0 {
+		fmt.Fprintf(w, "First run:\n")
+		writeSteps(w, p.PreSteps)
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "%s/\n", p.Root)
+
+	for i, e := range p.Entries {
+		if e.Path == p.Root {
+			continue
+		}
+
+		rel := strings.TrimPrefix(e.Path, p.Root+"/")
+		prefix := treePrefix(p.Entries, i, p.Root)
+
+		name := path.Base(rel)
+		if e.Dir {
+			fmt.Fprintf(w, "%s%s/\n", prefix, name)
+			continue
+		}
+
+		files++
+		size += e.Size
+		fmt.Fprintf(w, "%-56s %s %8d B\n", prefix+name, os.FileMode(e.Mode), e.Size)
+
+		// Continue the tree's lines below the file.
+		indent := strings.NewReplacer("├── ", "│   ", "└── ", "    ").Replace(prefix)
+		for _, imp := range e.Imports {
+			fmt.Fprintf(w, "%s    import %s -> %s\n", indent, imp.Old, imp.New)
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d files, %d bytes\n", files, size)
+
+	if len(p.Steps) > 0 {
+		fmt.Fprintf(w, "\nThen run:\n")
+		writeSteps(w, p.Steps)
+	}
+
+	return nil
+}
+
+func writeSteps(w io.Writer, steps []Step) {
+	for _, s := range steps {
+		fmt.Fprintf(w, "  (cd %s && %s)\n", s.Dir, strings.Join(s.Args, " "))
+	}
+}
+
+// treePrefix returns the tree drawing in front of entry i, e.g. '│   └── '.
+func treePrefix(entries []Entry, i int, root string) string {
+	rel := strings.TrimPrefix(entries[i].Path, root+"/")
+	parts := strings.Split(rel, "/")
+
+	var b strings.Builder
+	for depth := range parts {
+		ancestor := root + "/" + strings.Join(parts[:depth+1], "/")
+		last := isLastChild(entries, ancestor)
+
+		switch {
+		case depth < len(parts)-1 && last:
+			b.WriteString("    ")
+		case depth < len(parts)-1:
+			b.WriteString("│   ")
+		case last:
+			b.WriteString("└── ")
+		default:
+			b.WriteString("├── ")
+		}
+	}
+
+	return b.String()
+}
+
+// comparePaths orders paths element by element so that a directory's
+// children directly follow it, e.g. 'go', 'go/cmd', 'go.mod'.
+func comparePaths(a string, b string) int {
+	return slices.Compare(strings.Split(a, "/"), strings.Split(b, "/"))
+}
+
+// isLastChild reports whether no entry after p shares its parent directory.
+func isLastChild(entries []Entry, p string) bool {
+	parent := path.Dir(p)
+	i, _ := slices.BinarySearchFunc(entries, p, func(e Entry, target string) int {
+		return comparePaths(e.Path, target)
+	})
+
+	for _, e := range entries[i+1:] {
+		if path.Dir(e.Path) == parent {
+			return false
+		}
+	}
+	return true
+}
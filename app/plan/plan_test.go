@@ -0,0 +1,105 @@
+package plan
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"create-go-app.dev/fsys"
+	"create-go-app.dev/tmpl"
+)
+
+func TestBuild(t *testing.T) {
+	src := fstest.MapFS{
+		"embed/README.md.tmpl":   {Data: []byte("# {{.AppName}}\n")},
+		"embed/go/main.go":       {Data: []byte("package main\n\nimport _ \"github.com/username/repo/http\"\n")},
+		"embed/go.txt":           {Data: []byte("sorts after the go directory\n")},
+		"embed/skipped/file.txt": {Data: []byte("left out\n")},
+	}
+
+	fsys.EmbedPath = "embed"
+	t.Cleanup(func() {
+		fsys.EmbedPath = ""
+	})
+
+	p, err := Build(src, Options{
+		Name:      "my-app",
+		EmbedPath: "embed",
+		OldModule: "github.com/username/repo",
+		NewModule: "example.com/my-app",
+		Data:      tmpl.Data{AppName: "my-app"},
+		Skip: func(rel string) bool {
+			return rel == "skipped"
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, e := range p.Entries {
+		paths = append(paths, e.Path)
+	}
+
+	want := "my-app my-app/README.md my-app/go my-app/go/main.go my-app/go.txt"
+	if got := strings.Join(paths, " "); got != want {
+		t.Errorf("paths = %q, want = %q", got, want)
+	}
+
+	readme := p.Entries[1]
+	if readme.Size != len("# my-app\n") {
+		t.Errorf("size = %d, want = %d", readme.Size, len("# my-app\n"))
+	}
+
+	main := p.Entries[3]
+	if len(main.Imports) != 1 || main.Imports[0].New != "example.com/my-app/http" {
+		t.Errorf("imports = %+v", main.Imports)
+	}
+
+	p.AddPreStep(".", "git", "--version")
+	p.AddStep("my-app/go", "go", "fmt", "./...")
+
+	var tree bytes.Buffer
+	err = p.WriteTree(&tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"├── go/\n", "│   └── main.go", "│           import github.com/username/repo/http -> example.com/my-app/http", "└── go.txt"} {
+		if !strings.Contains(tree.String(), s) {
+			t.Errorf("missing %q in:\n%s", s, tree.String())
+		}
+	}
+
+	if !strings.HasPrefix(tree.String(), "First run:\n  (cd . && git --version)\n\nmy-app/\n") {
+		t.Errorf("pre steps aren't first in:\n%s", tree.String())
+	}
+	if !strings.HasSuffix(tree.String(), "Then run:\n  (cd my-app/go && go fmt ./...)\n") {
+		t.Errorf("steps aren't last in:\n%s", tree.String())
+	}
+}
+
+func TestAddFile(t *testing.T) {
+	p := &Plan{Root: "my-app", Entries: []Entry{
+		{Path: "my-app", Dir: true},
+		{Path: "my-app/go", Dir: true},
+		{Path: "my-app/go/main.go"},
+	}}
+
+	p.AddFile(".create-go-app/base/go/main.go", 13, fsys.FilePerm)
+	p.AddFile(".create-go-app.json", 100, fsys.FilePerm)
+
+	var paths []string
+	for _, e := range p.Entries {
+		paths = append(paths, e.Path)
+	}
+
+	want := "my-app my-app/.create-go-app my-app/.create-go-app/base my-app/.create-go-app/base/go my-app/.create-go-app/base/go/main.go my-app/.create-go-app.json my-app/go my-app/go/main.go"
+	if got := strings.Join(paths, " "); got != want {
+		t.Errorf("paths = %q, want = %q", got, want)
+	}
+	if !p.Entries[1].Dir || p.Entries[4].Dir || p.Entries[4].Size != 13 {
+		t.Errorf("entries = %+v", p.Entries)
+	}
+}
@@ -0,0 +1,64 @@
+// Package prompt reads answers from the user one line at a time.
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrNoInput is returned when stdin is closed before an answer is given.
+var ErrNoInput = errors.New("create-go-app: no input on stdin")
+
+// All prompts share one reader so that buffered input, e.g. piped answers,
+// isn't lost between questions.
+var (
+	Input            = bufio.NewReader(os.Stdin)
+	Output io.Writer = os.Stdout
+)
+
+// Line prints label and returns the next line of input without surrounding
+// whitespace. A final line without a trailing newline is accepted.
+func Line(label string) (string, error) {
+	fmt.Fprint(Output, label)
+
+	input, err := Input.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	input = strings.TrimSpace(input)
+	if errors.Is(err, io.EOF) && input == "" {
+		fmt.Fprintln(Output)
+		return "", ErrNoInput
+	}
+
+	return input, nil
+}
+
+// LineContext is Line, but returns ctx.Err() as soon as ctx is done, e.g. on
+// an interrupt. The pending read continues in the background and its answer
+// is dropped.
+func LineContext(ctx context.Context, label string) (string, error) {
+	type answer struct {
+		line string
+		err  error
+	}
+
+	answers := make(chan answer, 1)
+	go func() {
+		line, err := Line(label)
+		answers <- answer{line, err}
+	}()
+
+	select {
+	case a := <-answers:
+		return a.line, a.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
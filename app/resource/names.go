@@ -0,0 +1,171 @@
+package resource
+
+import (
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// Name is a resource or field name in every casing the templates need.
+type Name struct {
+	// e.g. 'BlogPost'
+	Pascal string
+	// e.g. 'blogPost'
+	Camel string
+	// e.g. 'blog_post'
+	Snake string
+	// e.g. 'blog-post'
+	Kebab string
+	// e.g. 'blog post'
+	Lower string
+}
+
+// Common initialisms that Go style writes in upper case, e.g. 'UserID'.
+var initialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "id": true, "ip": true,
+	"json": true, "sql": true, "uri": true, "url": true, "uuid": true,
+}
+
+// NewName splits s into words at '_', '-', spaces and lower to upper case
+// changes, e.g. 'blogPost', 'blog_post' and 'Blog Post' are the same name.
+func NewName(s string) Name {
+	return nameFromWords(words(s))
+}
+
+// Plural returns the name with its last word pluralized.
+func (n Name) Plural() Name {
+	w := words(n.Snake)
+	if len(w) == 0 {
+		return n
+	}
+	w[len(w)-1] = Pluralize(w[len(w)-1])
+	return nameFromWords(w)
+}
+
+func nameFromWords(w []string) Name {
+	var pascal, camel strings.Builder
+	for i, word := range w {
+		cased := word
+		if initialisms[word] {
+			cased = strings.ToUpper(word)
+		} else {
+			cased = strings.ToUpper(word[:1]) + word[1:]
+		}
+		pascal.WriteString(cased)
+		if i == 0 {
+			camel.WriteString(word)
+		} else {
+			camel.WriteString(cased)
+		}
+	}
+
+	return Name{
+		Pascal: pascal.String(),
+		Camel:  camel.String(),
+		Snake:  strings.Join(w, "_"),
+		Kebab:  strings.Join(w, "-"),
+		Lower:  strings.Join(w, " "),
+	}
+}
+
+// words returns the lower case words of s.
+func words(s string) []string {
+	var w []string
+	var cur []rune
+
+	flush := func() {
+		if len(cur) > 0 {
+			w = append(w, strings.ToLower(string(cur)))
+			cur = cur[:0]
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || unicode.IsSpace(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && len(cur) > 0:
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// Split 'blogPost' before 'P' and 'HTTPServer' before 'S'.
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+
+	return w
+}
+
+var irregularPlurals = map[string]string{
+	"child":  "children",
+	"foot":   "feet",
+	"goose":  "geese",
+	"man":    "men",
+	"mouse":  "mice",
+	"person": "people",
+	"tooth":  "teeth",
+	"woman":  "women",
+	"calf":   "calves",
+	"elf":    "elves",
+	"half":   "halves",
+	"knife":  "knives",
+	"leaf":   "leaves",
+	"life":   "lives",
+	"loaf":   "loaves",
+	"shelf":  "shelves",
+	"thief":  "thieves",
+	"wife":   "wives",
+	"wolf":   "wolves",
+	"echo":   "echoes",
+	"hero":   "heroes",
+	"potato": "potatoes",
+	"tomato": "tomatoes",
+}
+
+// Words that are the same in singular and plural.
+var uncountable = map[string]bool{
+	"data": true, "equipment": true, "fish": true, "information": true,
+	"metadata": true, "news": true, "series": true, "sheep": true,
+	"species": true,
+}
+
+// Pluralize returns the plural of a lower case English word.
+func Pluralize(word string) string {
+	if uncountable[word] {
+		return word
+	}
+	if p, ok := irregularPlurals[word]; ok {
+		return p
+	}
+
+	for _, suffix := range []string{"s", "x", "z", "ch", "sh"} {
+		if strings.HasSuffix(word, suffix) {
+			return word + "es"
+		}
+	}
+
+	if n := len(word); n > 1 && word[n-1] == 'y' && !strings.ContainsRune("aeiou", rune(word[n-2])) {
+		return word[:n-1] + "ies"
+	}
+
+	return word + "s"
+}
+
+// ident returns s, or s with a suffix when it isn't a usable Go identifier
+// in generated code, e.g. the keyword 'type'.
+func ident(s string, taken ...string) string {
+	for _, t := range taken {
+		if s == t {
+			return s + "Value"
+		}
+	}
+	if token.IsKeyword(s) {
+		return s + "Value"
+	}
+	return s
+}
@@ -0,0 +1,47 @@
+package resource
+
+import "testing"
+
+func TestNewName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Name
+	}{
+		{"BlogPost", Name{Pascal: "BlogPost", Camel: "blogPost", Snake: "blog_post", Kebab: "blog-post", Lower: "blog post"}},
+		{"blog_post", Name{Pascal: "BlogPost", Camel: "blogPost", Snake: "blog_post", Kebab: "blog-post", Lower: "blog post"}},
+		{"blog-post", Name{Pascal: "BlogPost", Camel: "blogPost", Snake: "blog_post", Kebab: "blog-post", Lower: "blog post"}},
+		{"user_id", Name{Pascal: "UserID", Camel: "userID", Snake: "user_id", Kebab: "user-id", Lower: "user id"}},
+		{"HTTPServer", Name{Pascal: "HTTPServer", Camel: "httpServer", Snake: "http_server", Kebab: "http-server", Lower: "http server"}},
+		{"avatarURL", Name{Pascal: "AvatarURL", Camel: "avatarURL", Snake: "avatar_url", Kebab: "avatar-url", Lower: "avatar url"}},
+	}
+
+	for _, tt := range tests {
+		if got := NewName(tt.in); got != tt.want {
+			t.Errorf("NewName(%q) = %+v, want = %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	tests := map[string]string{
+		"Thing":       "Things",
+		"BlogPost":    "BlogPosts",
+		"Category":    "Categories",
+		"Day":         "Days",
+		"Status":      "Statuses",
+		"Box":         "Boxes",
+		"Match":       "Matches",
+		"Person":      "People",
+		"SalesPerson": "SalesPeople",
+		"Leaf":        "Leaves",
+		"Hero":        "Heroes",
+		"Photo":       "Photos",
+		"Series":      "Series",
+	}
+
+	for in, want := range tests {
+		if got := NewName(in).Plural().Pascal; got != want {
+			t.Errorf("Plural(%q) = %q, want = %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+// Package resource generates a domain type, its Postgres service, HTTP
+// handlers, handler tests, a SQL migration and OpenAPI paths for a resource
+// in a generated HTTP project, e.g. 'generate resource BlogPost title:string'.
+package resource
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// Markers in generated projects that mark where resources are wired in.
+const (
+	MarkerServices = "// create-go-app:services"
+	MarkerRoutes   = "// create-go-app:routes"
+	MarkerPaths    = "# create-go-app:paths"
+	MarkerSchemas  = "# create-go-app:schemas"
+)
+
+// ErrNoMarker is returned when a file to patch has no marker comment.
+var ErrNoMarker = errors.New("create-go-app: marker comment not found")
+
+// FieldType maps a field type given on the command line to Go, SQL and
+// OpenAPI.
+type FieldType struct {
+	Go            string
+	SQL           string
+	OpenAPI       string
+	OpenAPIFormat string
+	// Go expression used as example data in handler tests.
+	Example string
+	// Example value in the OpenAPI schema.
+	ExampleYAML string
+}
+
+var fieldTypes = map[string]FieldType{
+	"string": {Go: "string", SQL: "VARCHAR(255)", OpenAPI: "string", Example: `"example"`, ExampleYAML: "example"},
+	"text":   {Go: "string", SQL: "TEXT", OpenAPI: "string", Example: `"example text"`, ExampleYAML: "example text"},
+	"int":    {Go: "int", SQL: "BIGINT", OpenAPI: "integer", OpenAPIFormat: "int64", Example: "42", ExampleYAML: "42"},
+	"float":  {Go: "float64", SQL: "DOUBLE PRECISION", OpenAPI: "number", OpenAPIFormat: "double", Example: "4.2", ExampleYAML: "4.2"},
+	"bool":   {Go: "bool", SQL: "BOOLEAN", OpenAPI: "boolean", Example: "true", ExampleYAML: "true"},
+	"time":   {Go: "time.Time", SQL: "TIMESTAMP", OpenAPI: "string", OpenAPIFormat: "date-time", Example: "time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)", ExampleYAML: "2024-01-02T03:04:05Z"},
+}
+
+// FieldTypes returns the supported field types.
+func FieldTypes() []string {
+	types := make([]string, 0, len(fieldTypes))
+	for t := range fieldTypes {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// Field is a column of the resource, e.g. 'title:string'.
+type Field struct {
+	Name Name
+	Type string
+	FieldType
+}
+
+// Resource is the data the templates are executed with.
+type Resource struct {
+	Name   Name
+	Plural Name
+	Fields []Field
+	// Module path of the project, e.g. 'github.com/username/my-app'.
+	Module string
+	// Name of the project's root package, e.g. 'thing'.
+	Package string
+}
+
+// Columns set by the database that fields can't be named.
+var reservedFields = []string{"id", "created_at"}
+
+// New validates name and fields, e.g. 'title:string', and returns the
+// resource.
+func New(name string, fields []string, module string, pkg string) (Resource, error) {
+	n := NewName(name)
+	if err := checkName(name, n); err != nil {
+		return Resource{}, err
+	}
+
+	if len(fields) == 0 {
+		return Resource{}, fmt.Errorf("create-go-app: resource '%s' needs at least one field, e.g. 'title:string'", n.Pascal)
+	}
+
+	r := Resource{
+		Name:    n,
+		Plural:  n.Plural(),
+		Module:  module,
+		Package: pkg,
+	}
+
+	if r.Plural.Snake == r.Name.Snake {
+		return Resource{}, fmt.Errorf("create-go-app: resource '%s' has no plural form, use a countable name", n.Pascal)
+	}
+
+	for _, arg := range fields {
+		fieldName, typ, ok := strings.Cut(arg, ":")
+		if !ok {
+			return Resource{}, fmt.Errorf("create-go-app: invalid field '%s', expected name:type", arg)
+		}
+
+		fn := NewName(fieldName)
+		if err := checkName(fieldName, fn); err != nil {
+			return Resource{}, err
+		}
+
+		ft, ok := fieldTypes[strings.ToLower(typ)]
+		if !ok {
+			return Resource{}, fmt.Errorf("create-go-app: invalid type '%s' for field '%s', expected one of: %s", typ, fieldName, strings.Join(FieldTypes(), ", "))
+		}
+
+		if slices.Contains(reservedFields, fn.Snake) {
+			return Resource{}, fmt.Errorf("create-go-app: field '%s' is reserved, every resource has 'id' and 'created_at'", fieldName)
+		}
+
+		for _, f := range r.Fields {
+			if f.Name.Snake == fn.Snake {
+				return Resource{}, fmt.Errorf("create-go-app: duplicate field '%s'", fieldName)
+			}
+		}
+
+		r.Fields = append(r.Fields, Field{Name: fn, Type: strings.ToLower(typ), FieldType: ft})
+	}
+
+	return r, nil
+}
+
+func checkName(s string, n Name) error {
+	if n.Snake == "" {
+		return fmt.Errorf("create-go-app: invalid name '%s'", s)
+	}
+	for _, r := range n.Snake {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			return fmt.Errorf("create-go-app: invalid name '%s', use ASCII letters and digits", s)
+		}
+	}
+	if unicode.IsDigit(rune(n.Snake[0])) {
+		return fmt.Errorf("create-go-app: invalid name '%s', it must start with a letter", s)
+	}
+	return nil
+}
+
+// Var is the variable name of a single resource.
+func (r Resource) Var() string {
+	return ident(r.Name.Camel, r.Package, r.Recv())
+}
+
+// PluralVar is the variable name of a slice of resources.
+func (r Resource) PluralVar() string {
+	return ident(r.Plural.Camel, r.Package, r.Recv())
+}
+
+// Recv is the name of the service receiver and parameters, e.g. 'ts' for a
+// thing service.
+func (r Resource) Recv() string {
+	return strings.ToLower(r.Name.Camel[:1]) + "s"
+}
+
+// PathParam is the name of the ID parameter in the OpenAPI spec.
+func (r Resource) PathParam() string {
+	return r.Name.Camel + "Id"
+}
+
+func (r Resource) Table() string {
+	return r.Plural.Snake
+}
+
+func (r Resource) IDColumn() string {
+	return r.Name.Snake + "_id"
+}
+
+// Columns are the columns of the fields, in order.
+func (r Resource) Columns() string {
+	cols := make([]string, len(r.Fields))
+	for i, f := range r.Fields {
+		cols[i] = f.Name.Snake
+	}
+	return strings.Join(cols, ", ")
+}
+
+func (r Resource) SelectColumns() string {
+	return r.IDColumn() + ", " + r.Columns() + ", created_at"
+}
+
+func (r Resource) Placeholders() string {
+	p := make([]string, len(r.Fields))
+	for i := range r.Fields {
+		p[i] = fmt.Sprintf("$%d", i+1)
+	}
+	return strings.Join(p, ", ")
+}
+
+func (r Resource) UpdateSet() string {
+	set := make([]string, len(r.Fields))
+	for i, f := range r.Fields {
+		set[i] = fmt.Sprintf("%s = $%d", f.Name.Snake, i+1)
+	}
+	return strings.Join(set, ", ")
+}
+
+func (r Resource) UpdateIDPlaceholder() string {
+	return fmt.Sprintf("$%d", len(r.Fields)+1)
+}
+
+// Args are the fields of v as query arguments.
+func (r Resource) Args(v string) string {
+	args := make([]string, len(r.Fields))
+	for i, f := range r.Fields {
+		args[i] = v + "." + f.Name.Pascal
+	}
+	return strings.Join(args, ", ")
+}
+
+// ScanArgs are pointers to every column of v in SelectColumns order.
+func (r Resource) ScanArgs(v string) string {
+	args := []string{"&" + v + ".ID"}
+	for _, f := range r.Fields {
+		args = append(args, "&"+v+"."+f.Name.Pascal)
+	}
+	args = append(args, "&"+v+".CreatedAt")
+	return strings.Join(args, ", ")
+}
+
+// HasTime reports whether a field is a time.Time.
+func (r Resource) HasTime() bool {
+	for _, f := range r.Fields {
+		if f.Type == "time" {
+			return true
+		}
+	}
+	return false
+}
+
+// Files renders the resource's new files. The keys are slash separated paths
+// relative to the project's root directory. now names the migration.
+func Files(r Resource, now time.Time) (map[string][]byte, error) {
+	files := map[string]string{
+		"domain.go.tmpl":    path.Join("go", r.Name.Snake+".go"),
+		"postgres.go.tmpl":  path.Join("go", "postgres", r.Plural.Snake+".go"),
+		"http.go.tmpl":      path.Join("go", "http", r.Plural.Snake+".go"),
+		"http_test.go.tmpl": path.Join("go", "http", r.Plural.Snake+"_test.go"),
+		"migration.sql.tmpl": path.Join("postgres", "migrations",
+			fmt.Sprintf("%s_create_%s.sql", now.UTC().Format("20060102150405"), r.Table())),
+	}
+
+	out := map[string][]byte{}
+	for name, dst := range files {
+		b, err := render(name, r)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasSuffix(dst, ".go") {
+			b, err = format.Source(b)
+			if err != nil {
+				return nil, fmt.Errorf("create-go-app: format %s: %w", dst, err)
+			}
+		}
+
+		out[dst] = b
+	}
+
+	return out, nil
+}
+
+// PatchMain wires the resource's Postgres service and routes into
+// 'go/cmd/main.go' at the marker comments.
+func PatchMain(src []byte, r Resource) ([]byte, error) {
+	service := fmt.Sprintf("%sService := postgres.New%sService(psql)\n", r.Var(), r.Name.Pascal)
+	routes := fmt.Sprintf("server.Register%sRoutes(ctx, %sService)\n", r.Name.Pascal, r.Var())
+
+	b, err := insertBefore(src, MarkerServices, service)
+	if err != nil {
+		return nil, err
+	}
+
+	return insertBefore(b, MarkerRoutes, routes)
+}
+
+// PatchOpenAPI adds the resource's paths and schema to 'swagger.yaml' at the
+// marker comments.
+func PatchOpenAPI(src []byte, r Resource) ([]byte, error) {
+	paths, err := render("paths.yaml.tmpl", r)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := render("schema.yaml.tmpl", r)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := insertBefore(src, MarkerPaths, string(paths))
+	if err != nil {
+		return nil, err
+	}
+
+	return insertBefore(b, MarkerSchemas, string(schema))
+}
+
+func render(name string, r Resource) ([]byte, error) {
+	t, err := template.New(name).Option("missingkey=error").ParseFS(templates, "templates/"+name)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = t.Execute(&buf, r)
+	if err != nil {
+		return nil, fmt.Errorf("create-go-app: render %s: %w", name, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// insertBefore inserts block before the line containing marker. Lines of
+// block that aren't already indented get the marker line's indentation.
+func insertBefore(src []byte, marker string, block string) ([]byte, error) {
+	i := bytes.Index(src, []byte(marker))
+	if i < 0 {
+		return nil, fmt.Errorf("%w: '%s'", ErrNoMarker, marker)
+	}
+
+	lineStart := bytes.LastIndexByte(src[:i], '\n') + 1
+	indent := string(src[lineStart:i])
+
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(block, "\n") {
+		if line == "" {
+			continue
+		}
+		if line != "\n" && !strings.HasPrefix(line, indent) {
+			b.WriteString(indent)
+		}
+		b.WriteString(line)
+	}
+
+	out := make([]byte, 0, len(src)+b.Len())
+	out = append(out, src[:lineStart]...)
+	out = append(out, b.String()...)
+	out = append(out, src[lineStart:]...)
+
+	return out, nil
+}
@@ -0,0 +1,118 @@
+package resource
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		title   string
+		name    string
+		fields  []string
+		wantErr bool
+	}{
+		{title: "Valid", name: "BlogPost", fields: []string{"title:string", "published_at:time"}},
+		{title: "No fields", name: "BlogPost", wantErr: true},
+		{title: "Invalid name", name: "9lives", fields: []string{"title:string"}, wantErr: true},
+		{title: "Uncountable name", name: "Sheep", fields: []string{"title:string"}, wantErr: true},
+		{title: "Missing type", name: "BlogPost", fields: []string{"title"}, wantErr: true},
+		{title: "Unknown type", name: "BlogPost", fields: []string{"title:varchar"}, wantErr: true},
+		{title: "Reserved field", name: "BlogPost", fields: []string{"id:int"}, wantErr: true},
+		{title: "Duplicate field", name: "BlogPost", fields: []string{"title:string", "Title:text"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			_, err := New(tt.name, tt.fields, "example.com/app", "thing")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got = %v, want = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	r, err := New("Type", []string{"title:string", "body:text", "views:int", "score:float", "draft:bool", "published_at:time"}, "example.com/app", "thing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := Files(r, time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"go/type.go",
+		"go/postgres/types.go",
+		"go/http/types.go",
+		"go/http/types_test.go",
+		"postgres/migrations/20240102030405_create_types.sql",
+	}
+
+	for _, name := range want {
+		b, ok := files[name]
+		if !ok {
+			t.Errorf("missing file %s", name)
+			continue
+		}
+
+		if strings.HasSuffix(name, ".go") {
+			_, err := parser.ParseFile(token.NewFileSet(), name, b, 0)
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+		}
+	}
+
+	if !strings.Contains(string(files["go/postgres/types.go"]), "typeValue thing.Type") {
+		t.Errorf("keyword 'type' was not renamed:\n%s", files["go/postgres/types.go"])
+	}
+}
+
+func TestPatch(t *testing.T) {
+	r, err := New("BlogPost", []string{"title:string"}, "example.com/app", "thing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	main := "func main() {\n\t" + MarkerServices + "\n\t" + MarkerRoutes + "\n}\n"
+
+	got, err := PatchMain([]byte(main), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "func main() {\n" +
+		"\tblogPostService := postgres.NewBlogPostService(psql)\n" +
+		"\t" + MarkerServices + "\n" +
+		"\tserver.RegisterBlogPostRoutes(ctx, blogPostService)\n" +
+		"\t" + MarkerRoutes + "\n}\n"
+
+	if string(got) != want {
+		t.Errorf("got = %q, want = %q", got, want)
+	}
+
+	_, err = PatchMain([]byte("func main() {}\n"), r)
+	if !errors.Is(err, ErrNoMarker) {
+		t.Errorf("got = %v, want = %v", err, ErrNoMarker)
+	}
+
+	spec := "paths:\n  " + MarkerPaths + "\ncomponents:\n  schemas:\n    " + MarkerSchemas + "\n"
+
+	got, err = PatchOpenAPI([]byte(spec), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"\n  /blog-posts:\n", "\n  /blog-posts/{blogPostId}:\n", "\n    BlogPost:\n"} {
+		if !strings.Contains(string(got), s) {
+			t.Errorf("missing %q in:\n%s", s, got)
+		}
+	}
+}
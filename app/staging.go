@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"create-go-app.dev/fsys"
+)
+
+// staging is the temporary directory an app is generated in, next to a new
+// target or inside an existing one, so it is on the target's file system and
+// in a directory known to be writable. It is renamed to the target, or its
+// files are moved into an existing target, only after every step succeeded,
+// so a failure or a killed process never leaves a half-written app behind.
+// A leftover staging directory is hidden and named after the target, e.g.
+// '.my-app.tmp-123'.
+type staging struct {
+	mu   sync.Mutex
+	path string
+	// Directory in path the app is generated in. Unlike path, which
+	// os.MkdirTemp makes private, it has the permissions of a directory
+	// created by the app, less the umask, and keeps them once renamed.
+	root string
+	// Topmost missing parent of a nested target that create made, removed
+	// along with the staging directory.
+	parent string
+}
+
+// create makes the staging directory inside target when it exists, e.g. for
+// 'create-go-app .', whose parent may be read-only, and next to it
+// otherwise, creating the target's missing parents. It fails once ctx is
+// done, so that after cancelling, started reliably reports whether anything
+// was written.
+func (s *staging) create(ctx context.Context, target string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	in := filepath.Dir(target)
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		in = target
+	}
+
+	parent := missingParent(in)
+	if parent != "" {
+		err := os.MkdirAll(in, fsys.DirPerm)
+		if err != nil {
+			return "", err
+		}
+		s.parent = parent
+	}
+
+	dir, err := os.MkdirTemp(in, "."+filepath.Base(target)+".tmp-")
+	if err != nil {
+		return "", err
+	}
+	s.path = dir
+
+	root := filepath.Join(dir, filepath.Base(target))
+	err = os.Mkdir(root, fsys.DirPerm)
+	if err != nil {
+		return "", err
+	}
+
+	s.root = root
+	return root, nil
+}
+
+// started reports whether a staging directory exists that hasn't been
+// committed or removed.
+func (s *staging) started() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.path != ""
+}
+
+// commit moves the generated files to target. A missing target is created
+// by atomically renaming the staging directory. Into an existing target the
+// files are moved one by one, except for the slash separated paths in keep,
+// whose existing files are left as they are.
+func (s *staging) commit(target string, keep map[string]bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// rename(2) would replace an empty directory, so existing targets,
+	// including ones created while the app was being generated, are merged.
+	if _, err := os.Stat(target); errors.Is(err, fs.ErrNotExist) {
+		err := os.Rename(s.root, target)
+		if err != nil {
+			return err
+		}
+
+		err = os.Remove(s.path)
+		if err != nil {
+			return err
+		}
+
+		s.path, s.root, s.parent = "", "", ""
+		return nil
+	}
+
+	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		if keep[filepath.ToSlash(rel)] {
+			return nil
+		}
+
+		dst := filepath.Join(target, rel)
+		err = os.MkdirAll(filepath.Dir(dst), fsys.DirPerm)
+		if err != nil {
+			return err
+		}
+		return os.Rename(path, dst)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = os.RemoveAll(s.path)
+	if err != nil {
+		return err
+	}
+
+	s.path, s.root, s.parent = "", "", ""
+	return nil
+}
+
+// remove deletes the staging directory and the parents create made if they
+// exist. It never touches the target.
+func (s *staging) remove() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, dir := range []string{s.path, s.parent} {
+		if dir == "" {
+			continue
+		}
+		err := os.RemoveAll(dir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	s.path, s.root, s.parent = "", "", ""
+	return nil
+}
+
+// missingParent returns the topmost directory of dir and its parents that
+// doesn't exist, or "" when dir exists.
+func missingParent(dir string) string {
+	missing := ""
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return missing
+		}
+		missing = dir
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return missing
+		}
+		dir = parent
+	}
+}
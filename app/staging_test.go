@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStaging(t *testing.T) {
+	tests := []struct {
+		title string
+		// Files in the target before generating, none when nil.
+		existing map[string]string
+		keep     map[string]bool
+		// Whether the target's parent is read-only.
+		readOnly bool
+		want     map[string]string
+	}{
+		{
+			title: "New target",
+			want:  map[string]string{"main.go": "generated", "README.md": "generated"},
+		},
+		{
+			title:    "Existing target",
+			existing: map[string]string{"README.md": "mine", "notes.txt": "mine"},
+			want:     map[string]string{"main.go": "generated", "README.md": "generated", "notes.txt": "mine"},
+		},
+		{
+			title:    "Kept files",
+			existing: map[string]string{"README.md": "mine"},
+			keep:     map[string]bool{"README.md": true},
+			want:     map[string]string{"main.go": "generated", "README.md": "mine"},
+		},
+		{
+			title:    "Existing target in a read-only directory",
+			existing: map[string]string{},
+			readOnly: true,
+			want:     map[string]string{"main.go": "generated", "README.md": "generated"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			parent := t.TempDir()
+			target := filepath.Join(parent, "my-app")
+			if tt.existing != nil {
+				writeFiles(t, target, tt.existing)
+			}
+			if tt.readOnly {
+				if os.Geteuid() == 0 {
+					t.Skip("root can write to read-only directories")
+				}
+				err := os.Chmod(parent, 0555)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { os.Chmod(parent, 0755) })
+			}
+
+			var s staging
+			root, err := s.create(context.Background(), target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !s.started() {
+				t.Error("not started after create")
+			}
+			writeFiles(t, root, map[string]string{"main.go": "generated", "README.md": "generated"})
+
+			err = s.commit(target, tt.keep)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.started() {
+				t.Error("started after commit")
+			}
+
+			entries, err := os.ReadDir(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(tt.want) {
+				t.Errorf("%d entries in the target, want %d, the staging directory is left", len(entries), len(tt.want))
+			}
+			for name, want := range tt.want {
+				b, err := os.ReadFile(filepath.Join(target, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(b) != want {
+					t.Errorf("%s = %q, want %q", name, b, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStagingRemove(t *testing.T) {
+	parent := t.TempDir()
+	target := filepath.Join(parent, "services", "api")
+
+	var s staging
+	_, err := s.create(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.remove()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The missing parents create made are removed too.
+	if _, err := os.Stat(filepath.Join(parent, "services")); !os.IsNotExist(err) {
+		t.Errorf("services is left behind: %v", err)
+	}
+}
+
+// writeFiles writes the files, by slash separated path, into dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, []byte(contents), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"create-go-app.dev/component"
+	"create-go-app.dev/manifest"
+	"create-go-app.dev/overlay"
+	"create-go-app.dev/prompt"
+	"create-go-app.dev/templates"
+)
+
+// vars is the value of the repeatable '-var name=value' flag.
+type vars map[string]string
+
+func (v vars) String() string {
+	pairs := make([]string, 0, len(v))
+	for name, value := range v {
+		pairs = append(pairs, name+"="+value)
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (v vars) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("create-go-app: invalid -var '%s', expected name=value", s)
+	}
+	v[name] = value
+	return nil
+}
+
+// templateCommand runs 'create-go-app template', which manages the registry
+// of named templates.
+func templateCommand(args []string) error {
+	usage := fmt.Errorf("create-go-app: usage: create-go-app template list | add <name> <dir> | remove <name>")
+	if len(args) == 0 {
+		return usage
+	}
+
+	path, err := templates.RegistryPath()
+	if err != nil {
+		return err
+	}
+
+	r, err := templates.ReadRegistry(path)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		if len(r.Templates) == 0 {
+			fmt.Printf("No templates registered in %s\n", path)
+			return nil
+		}
+		for _, name := range r.Names() {
+			fmt.Printf("%-16s %s\n", name, r.Templates[name])
+		}
+		return nil
+
+	case args[0] == "add" && len(args) == 3:
+		name := args[1]
+		if strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("create-go-app: invalid template name '%s', names can't look like paths", name)
+		}
+
+		dir, err := filepath.Abs(args[2])
+		if err != nil {
+			return err
+		}
+
+		// Only register templates that load.
+		t, err := templates.Load(dir)
+		if err != nil {
+			return err
+		}
+
+		r.Templates[name] = dir
+		err = r.Write(path)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Registered '%s' (%s) in %s, use it with -template=%s\n", name, t.Name, path, name)
+		return nil
+
+	case args[0] == "remove" && len(args) == 2:
+		if _, ok := r.Templates[args[1]]; !ok {
+			return fmt.Errorf("create-go-app: no template named '%s' in %s", args[1], path)
+		}
+
+		delete(r.Templates, args[1])
+		return r.Write(path)
+	}
+
+	return usage
+}
+
+// loadTemplate loads the user-supplied template ref, a directory or a name
+// in the registry. It also returns how the manifest refers to the template:
+// by its registered name, or by the directory's absolute path.
+func loadTemplate(ref string) (*templates.Template, string, error) {
+	r := &templates.Registry{}
+
+	// Without a config directory only template directories can be used.
+	if path, err := templates.RegistryPath(); err == nil {
+		r, err = templates.ReadRegistry(path)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	dir, err := r.Resolve(ref)
+	if err != nil {
+		return nil, "", err
+	}
+
+	t, err := templates.Load(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if _, ok := r.Templates[ref]; ok {
+		return t, ref, nil
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	return t, abs, nil
+}
+
+// templateType returns the project type of the template t and the files it
+// is generated from: the template's own, stacked over the embedded files of
+// its base type, if it has one.
+func templateType(t *templates.Template, embedded fs.FS) (projectType, fs.FS, error) {
+	pt := projectType{embedPath: ".", moduleDir: "."}
+	src := t.FS()
+
+	if t.Base != "" {
+		base, ok := projectTypes[t.Base]
+		if !ok {
+			return projectType{}, nil, fmt.Errorf("create-go-app: template '%s' has invalid base '%s', expected 'http' or 'cli'", t.Name, t.Base)
+		}
+
+		lower, err := fs.Sub(embedded, base.embedPath)
+		if err != nil {
+			return projectType{}, nil, err
+		}
+
+		// The template is the bottom overlay, so it can patch and delete
+		// the base's files like -overlay does.
+		src, err = overlay.Apply(lower, ".", []overlay.Layer{{Name: "template " + t.Name, FS: src}})
+		if err != nil {
+			return projectType{}, nil, err
+		}
+		pt.moduleDir = base.moduleDir
+		pt.exampleResource = base.exampleResource
+		pt.ports = base.ports
+		pt.components = append(component.Set{}, base.components...)
+	}
+
+	if t.ModuleDir != "" {
+		pt.moduleDir = t.ModuleDir
+	}
+
+	for _, c := range t.Components {
+		i := slices.IndexFunc(pt.components, func(b component.Component) bool { return b.Name == c.Name })
+		if i >= 0 {
+			pt.components[i] = c
+		} else {
+			pt.components = append(pt.components, c)
+		}
+	}
+
+	for _, c := range pt.components {
+		for _, req := range c.Requires {
+			if _, ok := pt.components.Lookup(req); !ok {
+				return projectType{}, nil, fmt.Errorf("create-go-app: template component '%s' requires unknown component '%s'", c.Name, req)
+			}
+		}
+	}
+
+	for _, h := range slices.Concat(t.Pre, t.Post) {
+		for _, name := range h.Components {
+			if _, ok := pt.components.Lookup(name); !ok {
+				return projectType{}, nil, fmt.Errorf("create-go-app: template hook '%s' runs with unknown component '%s'", strings.Join(h.Run, " "), name)
+			}
+		}
+	}
+
+	return pt, src, nil
+}
+
+// manifestType returns the project type and source files of the app
+// generated with m.
+func manifestType(m *manifest.Manifest) (projectType, fs.FS, error) {
+	pt, ok := projectTypes[m.Options.Type]
+	var src fs.FS = emb
+	if m.Options.Template != "" {
+		t, _, err := loadTemplate(m.Options.Template)
+		if err != nil {
+			return projectType{}, nil, err
+		}
+
+		pt, src, err = templateType(t, emb)
+		if err != nil {
+			return projectType{}, nil, err
+		}
+	} else if !ok {
+		return projectType{}, nil, fmt.Errorf("create-go-app: %s has unknown type '%s'", manifest.Name, m.Options.Type)
+	}
+
+	if len(m.Options.Overlays) == 0 {
+		return pt, src, nil
+	}
+
+	stack, _, err := applyOverlays(src, pt, m.Options.Overlays)
+	if err != nil {
+		return projectType{}, nil, err
+	}
+	return pt, stack, nil
+}
+
+// templateValues returns the values of the variables of the template t, from
+// -var, the user's answers when interactive, or their defaults.
+func templateValues(t *templates.Template, given map[string]string, interactive bool) (map[string]string, error) {
+	if !interactive {
+		return t.Values(given, nil)
+	}
+
+	return t.Values(given, func(v templates.Variable) (string, error) {
+		label := v.Name
+		if v.Description != "" {
+			label = fmt.Sprintf("%s (%s)", v.Description, v.Name)
+		}
+		if v.Default != "" {
+			label += fmt.Sprintf(" [%s]", v.Default)
+		}
+
+		for {
+			answer, err := prompt.Line(label + ": ")
+			if errors.Is(err, prompt.ErrNoInput) {
+				return "", fmt.Errorf("create-go-app: no value for template variable '%s' on stdin, pass -var %s=<value>", v.Name, v.Name)
+			}
+			if err != nil {
+				return "", err
+			}
+
+			if answer == "" {
+				answer = v.Default
+			}
+			if answer == "" && v.Required {
+				fmt.Fprintln(prompt.Output, "A value is required.")
+				continue
+			}
+			return answer, nil
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"create-go-app.dev/fsys"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RegistryName is the file in create-go-app's config directory that names
+// template directories, so they can be passed as '-template=<name>'.
+const RegistryName = "templates.yaml"
+
+// Registry maps template names to their directories.
+type Registry struct {
+	Templates map[string]string `yaml:"templates"`
+}
+
+// RegistryPath returns where the registry is kept, e.g.
+// '~/.config/create-go-app/templates.yaml' on Linux.
+func RegistryPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("create-go-app: no config directory for the template registry: %w", err)
+	}
+	return filepath.Join(dir, "create-go-app", RegistryName), nil
+}
+
+// ReadRegistry reads the registry at path. A missing file is an empty
+// registry.
+func ReadRegistry(path string) (*Registry, error) {
+	r := &Registry{Templates: map[string]string{}}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(b, r)
+	if err != nil {
+		return nil, fmt.Errorf("create-go-app: %s: %w", path, err)
+	}
+	if r.Templates == nil {
+		r.Templates = map[string]string{}
+	}
+	return r, nil
+}
+
+// Write saves the registry to path, creating its directory.
+func (r *Registry) Write(path string) error {
+	b, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), fsys.DirPerm)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, fsys.FilePerm)
+}
+
+// Names returns the registered names, sorted.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.Templates))
+	for name := range r.Templates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Resolve returns the directory of the template ref, which is either a path,
+// e.g. './platform' or '/srv/templates/platform', or a registered name. A
+// bare name that isn't registered is looked up as a directory.
+func (r *Registry) Resolve(ref string) (string, error) {
+	if filepath.IsAbs(ref) || strings.HasPrefix(ref, ".") || strings.ContainsRune(ref, '/') || strings.ContainsRune(ref, filepath.Separator) {
+		return ref, nil
+	}
+
+	if dir, ok := r.Templates[ref]; ok {
+		return dir, nil
+	}
+
+	if info, err := os.Stat(ref); err == nil && info.IsDir() {
+		return ref, nil
+	}
+
+	return "", fmt.Errorf("create-go-app: unknown template '%s', pass its directory or register it with 'create-go-app template add %s <dir>'", ref, ref)
+}
@@ -0,0 +1,78 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "create-go-app", RegistryName)
+
+	r, err := ReadRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Names()) != 0 {
+		t.Errorf("names = %v, want none", r.Names())
+	}
+
+	r.Templates["platform"] = "/srv/templates/platform"
+	r.Templates["cli"] = "/srv/templates/cli"
+
+	err = r.Write(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = ReadRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(r.Names(), " "); got != "cli platform" {
+		t.Errorf("names = %q, want = %q", got, "cli platform")
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(wd)
+	})
+
+	err = os.Mkdir("unregistered", 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{ref: "platform", want: "/srv/templates/platform"},
+		{ref: "./platform", want: "./platform"},
+		{ref: "templates/platform", want: "templates/platform"},
+		{ref: "/abs/platform", want: "/abs/platform"},
+		{ref: "unregistered", want: "unregistered"},
+		{ref: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := r.Resolve(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got = %v, want = %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got = %q, want = %q", got, tt.want)
+			}
+		})
+	}
+}
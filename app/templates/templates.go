@@ -0,0 +1,319 @@
+// Package templates loads user-supplied template directories, which are
+// described by a SpecName file and layered over or used in place of the
+// embedded templates.
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"create-go-app.dev/component"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SpecName is the file at the root of a template directory that describes
+// it. It isn't generated.
+const SpecName = "template.yaml"
+
+// Spec is the contents of a template's SpecName file.
+type Spec struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Embedded project type the template is layered over, e.g. 'http'. Its
+	// files replace the embedded ones of the same name. Without a base the
+	// template is used on its own.
+	Base string `yaml:"base"`
+	// Directory of the Go module relative to the app's root. Defaults to the
+	// base's, or the root without a base.
+	ModuleDir string `yaml:"moduleDir"`
+	// Values the template's files use as {{.Vars.<name>}}.
+	Variables []Variable `yaml:"variables"`
+	// Optional parts of the template, added to the base's. A component
+	// named like one of the base's replaces it.
+	Components component.Set `yaml:"components"`
+	// Commands run in order before any file of the app is written, in the
+	// working directory.
+	Pre []Hook `yaml:"pre"`
+	// Commands run in order once the app is generated and formatted, in the
+	// app's directory.
+	Post []Hook `yaml:"post"`
+}
+
+// Variable is a value the user gives when generating from the template.
+type Variable struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Default     string `yaml:"default"`
+	// Whether generating fails when the variable has no value.
+	Required bool `yaml:"required"`
+}
+
+// Hook is a command run before or after generating an app.
+type Hook struct {
+	Name string `yaml:"name"`
+	// Slash separated directory the command runs in, relative to the
+	// directory of the hook, e.g. 'node'.
+	Dir string `yaml:"dir"`
+	// Command and its arguments, e.g. ['go', 'generate', './...'].
+	Run []string `yaml:"run"`
+	// Environment variables added to the command's environment.
+	Env map[string]string `yaml:"env"`
+	// Components that have to be selected for the hook to run, e.g.
+	// ['node'].
+	Components []string `yaml:"components"`
+	// How long the command may run, e.g. '5m', without a limit when zero.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Template is a template directory.
+type Template struct {
+	Spec
+	// Directory the template was loaded from.
+	Dir string
+}
+
+// Load reads and checks the SpecName file of the template directory dir.
+func Load(dir string) (*Template, error) {
+	b, err := os.ReadFile(filepath.Join(dir, SpecName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("create-go-app: %s has no %s, is it a template directory", dir, SpecName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("create-go-app: %s: %w", filepath.Join(dir, SpecName), err)
+	}
+
+	return &Template{Spec: *spec, Dir: dir}, nil
+}
+
+// Parse decodes and checks the contents of a SpecName file. Unknown fields
+// are an error, so typos don't go unnoticed.
+func Parse(b []byte) (*Spec, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+
+	var spec Spec
+	err := dec.Decode(&spec)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	err = spec.check()
+	if err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+func (s *Spec) check() error {
+	if s.Name == "" {
+		return errors.New("missing name")
+	}
+
+	if s.ModuleDir != "" && !fs.ValidPath(s.ModuleDir) {
+		return fmt.Errorf("moduleDir '%s' isn't a slash separated path in the template", s.ModuleDir)
+	}
+
+	var names []string
+	for _, v := range s.Variables {
+		if !isIdentifier(v.Name) {
+			return fmt.Errorf("variable name '%s' isn't an identifier, e.g. 'team_name'", v.Name)
+		}
+		if slices.Contains(names, v.Name) {
+			return fmt.Errorf("variable '%s' is declared twice", v.Name)
+		}
+		names = append(names, v.Name)
+	}
+
+	names = nil
+	for _, c := range s.Components {
+		if c.Name == "" {
+			return errors.New("component without a name")
+		}
+		if slices.Contains(names, c.Name) {
+			return fmt.Errorf("component '%s' is declared twice", c.Name)
+		}
+		names = append(names, c.Name)
+
+		for _, p := range c.Paths {
+			if !fs.ValidPath(p) || p == "." {
+				return fmt.Errorf("component '%s' path '%s' isn't a slash separated path in the template", c.Name, p)
+			}
+		}
+	}
+
+	for _, hooks := range []struct {
+		kind  string
+		hooks []Hook
+	}{{"pre", s.Pre}, {"post", s.Post}} {
+		for i, h := range hooks.hooks {
+			err := h.check()
+			if err != nil {
+				return fmt.Errorf("%s hook %d %w", hooks.kind, i+1, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (h Hook) check() error {
+	if len(h.Run) == 0 || h.Run[0] == "" {
+		return errors.New("has no command to run")
+	}
+	if h.Dir != "" && !fs.ValidPath(h.Dir) {
+		return fmt.Errorf("dir '%s' isn't a slash separated path", h.Dir)
+	}
+	for name := range h.Env {
+		if name == "" || strings.ContainsAny(name, "= ") {
+			return fmt.Errorf("env name '%s' isn't a variable name", name)
+		}
+	}
+	if h.Timeout < 0 {
+		return fmt.Errorf("timeout %s is negative", h.Timeout)
+	}
+	return nil
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case i > 0 && '0' <= r && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// FS returns the template's files, without SpecName.
+func (t *Template) FS() fs.FS {
+	return withoutSpec{os.DirFS(t.Dir)}
+}
+
+// withoutSpec hides SpecName at the root of a template directory.
+type withoutSpec struct {
+	fs.FS
+}
+
+func (w withoutSpec) Open(name string) (fs.File, error) {
+	if name == SpecName {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	f, err := w.FS.Open(name)
+	if err != nil || name != "." {
+		return f, err
+	}
+	return &root{File: f}, nil
+}
+
+func (w withoutSpec) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(w.FS, name)
+	if err != nil || name != "." {
+		return entries, err
+	}
+	return slices.DeleteFunc(entries, isSpec), nil
+}
+
+func isSpec(e fs.DirEntry) bool {
+	return e.Name() == SpecName
+}
+
+// root is the root directory of a template, listed without SpecName.
+type root struct {
+	fs.File
+}
+
+func (r *root) ReadDir(n int) ([]fs.DirEntry, error) {
+	d, ok := r.File.(fs.ReadDirFile)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: ".", Err: errors.New("not a directory")}
+	}
+
+	for {
+		entries, err := d.ReadDir(n)
+		entries = slices.DeleteFunc(entries, isSpec)
+		// Reading only the spec would look like the end of the directory.
+		if len(entries) > 0 || err != nil || n <= 0 {
+			return entries, err
+		}
+	}
+}
+
+// Values returns the value of every variable of the template: the one given,
+// e.g. with -var, the answer of ask when it isn't nil, or the default. A
+// given name the template doesn't declare, or a required variable without a
+// value, is an error.
+func (t *Template) Values(given map[string]string, ask func(v Variable) (string, error)) (map[string]string, error) {
+	for name := range given {
+		if !slices.ContainsFunc(t.Variables, func(v Variable) bool { return v.Name == name }) {
+			return nil, fmt.Errorf("create-go-app: template '%s' has no variable '%s'", t.Name, name)
+		}
+	}
+
+	values := map[string]string{}
+	for _, v := range t.Variables {
+		value, ok := given[v.Name]
+		switch {
+		case ok:
+		case ask != nil:
+			answer, err := ask(v)
+			if err != nil {
+				return nil, err
+			}
+			value = answer
+		default:
+			value = v.Default
+		}
+
+		if v.Required && value == "" {
+			return nil, fmt.Errorf("create-go-app: template variable '%s' is required, pass -var %s=<value>", v.Name, v.Name)
+		}
+		values[v.Name] = value
+	}
+
+	return values, nil
+}
+
+// HookDir returns the directory the hook runs in, below root.
+func HookDir(root string, h Hook) string {
+	return filepath.Join(root, filepath.FromSlash(h.Dir))
+}
+
+// Applies reports whether the hook runs for the selected components.
+func (h Hook) Applies(selected []string) bool {
+	for _, c := range h.Components {
+		if !slices.Contains(selected, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Environ returns the hook's environment variables as 'name=value', sorted.
+func (h Hook) Environ() []string {
+	env := make([]string, 0, len(h.Env))
+	for name, value := range h.Env {
+		env = append(env, name+"="+value)
+	}
+	slices.Sort(env)
+	return env
+}
@@ -0,0 +1,220 @@
+package templates
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+const spec = `name: platform
+description: HTTP server with the platform team's logging
+base: http
+variables:
+  - name: team
+    description: Owning team
+    required: true
+  - name: region
+    default: eu-west-1
+components:
+  - name: metrics
+    summary: Prometheus metrics endpoint
+    paths: [go/metrics]
+    requires: [redis]
+pre:
+  - run: [git, --version]
+post:
+  - name: Generate
+    dir: go
+    run: [go, generate, ./...]
+  - name: Install packages
+    dir: node
+    run: [npm, install]
+    env:
+      NPM_CONFIG_FUND: "false"
+      CI: "1"
+    components: [node]
+    timeout: 5m
+`
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Name != "platform" || s.Base != "http" {
+		t.Errorf("name = %q, base = %q", s.Name, s.Base)
+	}
+	if len(s.Variables) != 2 || !s.Variables[0].Required || s.Variables[1].Default != "eu-west-1" {
+		t.Errorf("variables = %+v", s.Variables)
+	}
+	if len(s.Components) != 1 || s.Components[0].Paths[0] != "go/metrics" || s.Components[0].Requires[0] != "redis" {
+		t.Errorf("components = %+v", s.Components)
+	}
+	if len(s.Pre) != 1 || len(s.Post) != 2 || strings.Join(s.Post[0].Run, " ") != "go generate ./..." {
+		t.Errorf("pre = %+v, post = %+v", s.Pre, s.Post)
+	}
+	if h := s.Post[1]; h.Timeout != 5*time.Minute || !slices.Equal(h.Environ(), []string{"CI=1", "NPM_CONFIG_FUND=false"}) {
+		t.Errorf("post[1] = %+v", h)
+	}
+
+	tests := []struct {
+		title string
+		spec  string
+		want  string
+	}{
+		{title: "Missing name", spec: "base: http\n", want: "missing name"},
+		{title: "Unknown field", spec: "name: x\nbse: http\n", want: "field bse not found"},
+		{title: "Variable name", spec: "name: x\nvariables:\n  - name: team-name\n", want: "isn't an identifier"},
+		{title: "Duplicate variable", spec: "name: x\nvariables:\n  - name: a\n  - name: a\n", want: "declared twice"},
+		{title: "Duplicate component", spec: "name: x\ncomponents:\n  - name: a\n  - name: a\n", want: "declared twice"},
+		{title: "Component path", spec: "name: x\ncomponents:\n  - name: a\n    paths: [../a]\n", want: "isn't a slash separated path"},
+		{title: "Empty hook", spec: "name: x\npost:\n  - name: nothing\n", want: "post hook 1 has no command"},
+		{title: "Hook dir", spec: "name: x\npre:\n  - run: [ls]\n    dir: /tmp\n", want: "isn't a slash separated path"},
+		{title: "Hook env", spec: "name: x\npost:\n  - run: [ls]\n    env: {\"A=B\": c}\n", want: "isn't a variable name"},
+		{title: "Hook timeout", spec: "name: x\npost:\n  - run: [ls]\n    timeout: -1s\n", want: "negative"},
+		{title: "Hook timeout unit", spec: "name: x\npost:\n  - run: [ls]\n    timeout: 5\n", want: "cannot unmarshal"},
+		{title: "Module dir", spec: "name: x\nmoduleDir: ../go\n", want: "isn't a slash separated path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			_, err := Parse([]byte(tt.spec))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got = %v, want = %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		SpecName:          spec,
+		"README.md.tmpl":  "# {{.AppName}}\n",
+		"go/metrics/m.go": "package metrics\n",
+		"a.txt":           "sorts before the spec\n",
+		"z.txt":           "sorts after the spec\n",
+	}
+	for name, contents := range files {
+		err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tmpl, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Name != "platform" || tmpl.Dir != dir {
+		t.Errorf("name = %q, dir = %q", tmpl.Name, tmpl.Dir)
+	}
+
+	// The spec describes the template and isn't generated.
+	err = fstest.TestFS(tmpl.FS(), "README.md.tmpl", "go/metrics/m.go", "a.txt", "z.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat(tmpl.FS(), SpecName); err == nil {
+		t.Errorf("%s is listed in the template's files", SpecName)
+	}
+
+	_, err = Load(t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "has no "+SpecName) {
+		t.Errorf("got = %v, want a missing %s error", err, SpecName)
+	}
+}
+
+func TestValues(t *testing.T) {
+	s, err := Parse([]byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &Template{Spec: *s}
+
+	tests := []struct {
+		title   string
+		given   map[string]string
+		ask     func(v Variable) (string, error)
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			title: "Given and default",
+			given: map[string]string{"team": "payments"},
+			want:  map[string]string{"team": "payments", "region": "eu-west-1"},
+		},
+		{
+			title: "Asked",
+			ask: func(v Variable) (string, error) {
+				return "answer-" + v.Name, nil
+			},
+			want: map[string]string{"team": "answer-team", "region": "answer-region"},
+		},
+		{
+			title:   "Required",
+			wantErr: "'team' is required",
+		},
+		{
+			title:   "Unknown",
+			given:   map[string]string{"team": "payments", "owner": "me"},
+			wantErr: "has no variable 'owner'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got, err := tmpl.Values(tt.given, tt.ask)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got = %v, want = %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for name, want := range tt.want {
+				if got[name] != want {
+					t.Errorf("%s = %q, want = %q", name, got[name], want)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("values = %v, want = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHookApplies(t *testing.T) {
+	h := Hook{Run: []string{"npm", "install"}, Components: []string{"node", "playwright"}}
+
+	tests := []struct {
+		selected []string
+		want     bool
+	}{
+		{selected: []string{"postgres", "node", "playwright"}, want: true},
+		{selected: []string{"node"}, want: false},
+		{selected: nil, want: false},
+	}
+	for _, tt := range tests {
+		if got := h.Applies(tt.selected); got != tt.want {
+			t.Errorf("Applies(%q) = %v, want = %v", tt.selected, got, tt.want)
+		}
+	}
+
+	if !(Hook{}).Applies(nil) {
+		t.Error("a hook without components doesn't apply")
+	}
+}
@@ -0,0 +1,141 @@
+// Package tmpl renders embedded scaffold files ending in Suffix through
+// text/template.
+package tmpl
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	"create-go-app.dev/license"
+)
+
+// Suffix marks an embedded file as a template. It is removed from the name of
+// the generated file, e.g. 'docker-compose.yml.tmpl' -> 'docker-compose.yml'.
+const Suffix = ".tmpl"
+
+// Data is the model every template is executed with.
+type Data struct {
+	// Name of the app, e.g. 'my-app'.
+	AppName string
+	// Go module path, e.g. 'github.com/username/my-app'.
+	ModulePath string
+	// Go version without the 'go' prefix, e.g. '1.23.5'.
+	GoVersion string
+	// Selected components, e.g. 'postgres'.
+	Components []string
+	// Domain resources served by the app.
+	Resources []Resource
+	// Ports published on the host.
+	Ports Ports
+	// Values of a user-supplied template's variables, e.g. {{.Vars.team}}.
+	Vars map[string]string
+	// License of the app, e.g. 'MIT', or 'none'.
+	License string
+	// Copyright holder and year in the license, e.g. 'Jane Doe' and 2026.
+	Author string
+	Year   int
+}
+
+// Resource is a domain type, e.g. 'Thing', with its plural form.
+type Resource struct {
+	Name   string
+	Plural string
+}
+
+// Ports are the host ports each service is published on in
+// docker-compose.yml.
+type Ports struct {
+	Go            int `json:"go"`
+	Postgres      int `json:"postgres"`
+	Redis         int `json:"redis"`
+	SwaggerUI     int `json:"swaggerUI"`
+	SwaggerEditor int `json:"swaggerEditor"`
+	Node          int `json:"node"`
+}
+
+// DefaultPorts returns the ports used when the user doesn't choose their own.
+func DefaultPorts() Ports {
+	return Ports{
+		Go:            1111,
+		Postgres:      2222,
+		Redis:         3333,
+		SwaggerUI:     4444,
+		SwaggerEditor: 5555,
+		Node:          7777,
+	}
+}
+
+// CheckGoVersion returns an error when v isn't a Go release version the
+// templates can use, e.g. '1.23' or '1.23.5'.
+func CheckGoVersion(v string) error {
+	parts := strings.Split(v, ".")
+	valid := len(parts) >= 2 && len(parts) <= 3 && parts[0] == "1"
+	for _, p := range parts {
+		if p == "" || strings.Trim(p, "0123456789") != "" {
+			valid = false
+		}
+	}
+	if !valid {
+		return fmt.Errorf("create-go-app: invalid Go version '%s', expected e.g. '1.23.5'", v)
+	}
+	return nil
+}
+
+// Has reports whether the component was selected.
+func (d Data) Has(component string) bool {
+	return slices.Contains(d.Components, component)
+}
+
+// LicenseText returns the text of the app's license for its author and
+// year.
+func (d Data) LicenseText() (string, error) {
+	return license.Text(d.License, d.Author, d.Year)
+}
+
+// Identifier returns AppName as a lower case identifier that is safe to use as
+// a database, user or environment variable name, e.g. 'my-app' -> 'my_app'.
+func (d Data) Identifier() string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(d.AppName) {
+		switch {
+		case 'a' <= r && r <= 'z', '0' <= r && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0:
+			b.WriteByte('_')
+		}
+	}
+
+	id := strings.Trim(b.String(), "_")
+	if id == "" {
+		return "app"
+	}
+	if id[0] >= '0' && id[0] <= '9' {
+		return "app_" + id
+	}
+	return id
+}
+
+var funcs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// Render executes the template src with data. name is only used in error
+// messages. Referencing a missing key is an error.
+func Render(name string, src []byte, data Data) ([]byte, error) {
+	t, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("create-go-app: parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	err = t.Execute(&buf, data)
+	if err != nil {
+		return nil, fmt.Errorf("create-go-app: render template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,120 @@
+package tmpl
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	data := Data{
+		AppName:    "my-app",
+		ModulePath: "example.com/my-app",
+		Components: []string{"postgres"},
+		Ports:      DefaultPorts(),
+		Vars:       map[string]string{"team": "payments"},
+		License:    "MIT",
+		Author:     "Jane Doe",
+		Year:       2026,
+	}
+
+	tests := []struct {
+		title   string
+		src     string
+		want    string
+		wantErr bool
+	}{
+		{
+			title: "Plain text",
+			src:   "no actions",
+			want:  "no actions",
+		},
+		{
+			title: "Fields",
+			src:   "{{.AppName}} {{.ModulePath}} {{.Ports.Go}}",
+			want:  "my-app example.com/my-app 1111",
+		},
+		{
+			title: "Components",
+			src:   "{{if .Has \"postgres\"}}pg{{end}}{{if .Has \"redis\"}}redis{{end}}",
+			want:  "pg",
+		},
+		{
+			title: "Identifier",
+			src:   "{{.Identifier}} {{upper .Identifier}}",
+			want:  "my_app MY_APP",
+		},
+		{
+			title: "Vars",
+			src:   "{{.Vars.team}}",
+			want:  "payments",
+		},
+		{
+			title: "License",
+			src:   "{{printf \"%.11s\" .LicenseText}}",
+			want:  "MIT License",
+		},
+		{
+			title: "License holder and year",
+			src:   "{{slice .LicenseText 13 40}}",
+			want:  "Copyright (c) 2026 Jane Doe",
+		},
+		{
+			title:   "Parse error",
+			src:     "{{if}}",
+			wantErr: true,
+		},
+		{
+			title:   "Missing field",
+			src:     "{{.Missing}}",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got, err := Render("test.tmpl", []byte(tt.src), data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got = %v, want = %v", err, tt.wantErr)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("got = %q, want = %q", string(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"my-app":      "my_app",
+		"My App!":     "my_app",
+		"--api--":     "api",
+		"9lives":      "app_9lives",
+		"services.v2": "services_v2",
+		"":            "app",
+	}
+
+	for name, want := range tests {
+		got := Data{AppName: name}.Identifier()
+		if got != want {
+			t.Errorf("Identifier(%q) = %q, want = %q", name, got, want)
+		}
+	}
+}
+
+func TestCheckGoVersion(t *testing.T) {
+	tests := map[string]bool{
+		"1.23.5":   true,
+		"1.24":     true,
+		"go1.23.5": false,
+		"1":        false,
+		"1.23.":    false,
+		"1.23rc1":  false,
+		"2.0":      false,
+		"":         false,
+	}
+
+	for v, valid := range tests {
+		err := CheckGoVersion(v)
+		if (err == nil) != valid {
+			t.Errorf("CheckGoVersion(%q) = %v, want valid = %v", v, err, valid)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"create-go-app.dev/fsys"
+	"create-go-app.dev/gotools"
+	"create-go-app.dev/license"
+	"create-go-app.dev/manifest"
+	"create-go-app.dev/merge"
+	"create-go-app.dev/tmpl"
+
+	"github.com/fatih/color"
+)
+
+// What upgrade does with a generated file.
+const (
+	upgradeUnchanged = "unchanged" // the template's output didn't change
+	upgradeKept      = "kept"      // only the user changed the file
+	upgradeUpdated   = "updated"   // only the template changed, it is replaced
+	upgradeMerged    = "merged"    // both changed, merged without conflicts
+	upgradeConflict  = "conflict"  // both changed the same lines
+	upgradeAdded     = "added"     // new template
+	upgradeDeleted   = "deleted"   // the user deleted the file, it stays deleted
+	upgradeObsolete  = "obsolete"  // the template was removed, the file is left alone
+	upgradeYours     = "yours"     // the generator didn't write the file, e.g. it was kept with -on-conflict
+)
+
+// upgradeChange is the outcome of upgrading one file.
+type upgradeChange struct {
+	file   manifest.File
+	action string
+	// Contents to write, nil to leave the file as it is.
+	contents []byte
+	// Rejected template changes with -conflict=rej.
+	rej []byte
+	// The new template's output, saved as the base of the next upgrade.
+	theirs []byte
+}
+
+// upgrade runs 'create-go-app upgrade', which merges the changes to the
+// templates since the project in -dir was generated into its files.
+func upgrade(args []string) error {
+	flags := flag.NewFlagSet("upgrade", flag.ContinueOnError)
+	dir := flags.String("dir", ".", "root directory of the generated project")
+	conflictStyle := flags.String("conflict", "markers", "how conflicts are written, 'markers' in the file or a 'rej' file next to it")
+	dryRun := flags.Bool("dry-run", false, "print what would change without writing anything")
+
+	flags.Usage = func() {
+		fmt.Printf("Usage: create-go-app upgrade [flags]\n")
+		fmt.Printf("  Merges the changes to the templates since the project was generated into its files.\n")
+		fmt.Printf("  Files you didn't edit are replaced, edited files are three-way merged and\n")
+		fmt.Printf("  conflicting hunks are written between conflict markers or to a .rej file.\n")
+		flags.PrintDefaults()
+	}
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *conflictStyle != "markers" && *conflictStyle != "rej" {
+		return fmt.Errorf("create-go-app: invalid -conflict '%s', expected 'markers' or 'rej'", *conflictStyle)
+	}
+
+	m, err := manifest.Read(*dir)
+	if err != nil {
+		return err
+	}
+
+	pt, src, err := manifestType(m)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	next, tmp, err := renderProject(ctx, src, pt, m.Options, m.Options.Module, m.Options.Components)
+	if tmp != "" {
+		defer os.RemoveAll(tmp)
+	}
+	if err != nil {
+		return err
+	}
+
+	changes, err := upgradeChanges(*dir, m, next, tmp, *conflictStyle == "rej")
+	if err != nil {
+		return err
+	}
+
+	conflicts := 0
+	counts := map[string]int{}
+	for _, c := range changes {
+		counts[c.action]++
+		if c.action == upgradeConflict {
+			conflicts++
+		}
+		if c.action != upgradeUnchanged {
+			printUpgradeChange(c)
+		}
+	}
+
+	fmt.Fprintf(color.Output, "Upgrade from %s to %s: ", m.Generator.Version, generatorVersion())
+	for i, action := range []string{upgradeUpdated, upgradeMerged, upgradeConflict, upgradeAdded, upgradeKept, upgradeYours, upgradeDeleted, upgradeObsolete, upgradeUnchanged} {
+		if i > 0 {
+			fmt.Print(", ")
+		}
+		fmt.Printf("%d %s", counts[action], action)
+	}
+	fmt.Println()
+
+	if *dryRun {
+		return nil
+	}
+
+	err = applyUpgrade(*dir, m, changes)
+	if err != nil {
+		return err
+	}
+
+	if conflicts > 0 {
+		return fmt.Errorf("create-go-app: %d file(s) have conflicts, resolve them before committing", conflicts)
+	}
+	return nil
+}
+
+// renderProject generates a project with the current templates in src, the
+// options it was generated with and the given module path and components
+// into a temporary directory, which the caller removes.
+func renderProject(ctx context.Context, src fs.FS, pt projectType, opts manifest.Options, module string, components []string) (*manifest.Manifest, string, error) {
+	tmp, err := os.MkdirTemp("", "create-go-app-render-")
+	if err != nil {
+		return nil, "", err
+	}
+
+	data := tmpl.Data{
+		AppName:    opts.Name,
+		ModulePath: module,
+		GoVersion:  opts.GoVersion,
+		Components: components,
+		Resources:  []tmpl.Resource{{Name: "Thing", Plural: "Things"}},
+		Ports:      opts.Ports,
+		License:    cmp.Or(opts.License, license.MIT),
+		// Apps generated before the holder and year were recorded get the
+		// defaults, rather than whoever runs the command.
+		Author: cmp.Or(opts.Author, defaultAuthor(opts.Name)),
+		Year:   cmp.Or(opts.Year, time.Now().Year()),
+		Vars:   opts.Vars,
+	}
+
+	next := &manifest.Manifest{}
+	err = render(ctx, src, pt, components, data, fsys.OS(tmp), next)
+	if err != nil {
+		return nil, tmp, err
+	}
+
+	// Format like a freshly generated app, quietly since only failures
+	// matter here.
+	if dir := filepath.Join(tmp, pt.moduleDir); isModule(dir) {
+		_, err = (&gotools.Runner{}).FormatCode(ctx, dir)
+		if err != nil {
+			return nil, tmp, err
+		}
+	}
+
+	return next, tmp, next.Hash(os.DirFS(tmp))
+}
+
+// upgradeChanges decides what happens to each file of the project in dir
+// generated with m, given the files of next rendered into tmp.
+func upgradeChanges(dir string, m *manifest.Manifest, next *manifest.Manifest, tmp string, rej bool) ([]upgradeChange, error) {
+	labels := merge.Labels{
+		Ours:   "yours",
+		Base:   "create-go-app " + m.Generator.Version,
+		Theirs: "create-go-app " + generatorVersion(),
+	}
+
+	var changes []upgradeChange
+	for _, f := range next.Files {
+		theirs, err := os.ReadFile(filepath.Join(tmp, filepath.FromSlash(f.Path)))
+		if err != nil {
+			return nil, err
+		}
+		c := upgradeChange{file: f, theirs: theirs}
+		generated, ok := m.Lookup(f.Path)
+
+		ours, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if errors.Is(err, fs.ErrNotExist) {
+			if ok {
+				c.action = upgradeDeleted
+			} else {
+				c.action, c.contents = upgradeAdded, theirs
+			}
+			changes = append(changes, c)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Files that were in the app's directory before it was generated
+		// and kept, or added by hand at a template's path since, are left
+		// to the user.
+		if !ok {
+			c.action = upgradeYours
+			changes = append(changes, c)
+			continue
+		}
+
+		// Without a saved base, e.g. when the file holds secrets or the
+		// project was cloned without its base, the checksum
+		// in the manifest still tells whether the template's output or the
+		// file changed. If both did, the whole file is a conflict unless it
+		// already matches.
+		base, err := manifest.Base(dir, f.Path)
+		hasBase := err == nil
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		kept := hasBase && bytes.Equal(base, theirs)
+		untouched := hasBase && bytes.Equal(ours, base)
+		if !hasBase {
+			kept = generated.SHA256 == manifest.Checksum(theirs)
+			untouched = generated.SHA256 == manifest.Checksum(ours)
+		}
+
+		switch {
+		case bytes.Equal(ours, theirs):
+			c.action = upgradeUnchanged
+		case kept:
+			c.action = upgradeKept
+		case untouched:
+			c.action, c.contents = upgradeUpdated, theirs
+		default:
+			res := merge.Three(base, ours, theirs, labels)
+			switch {
+			case res.Conflicts == 0:
+				c.action, c.contents = upgradeMerged, res.Merged
+			case rej:
+				c.action = upgradeConflict
+				c.rej = merge.Unified("a/"+f.Path, "b/"+f.Path, base, theirs)
+			default:
+				c.action, c.contents = upgradeConflict, res.Merged
+			}
+		}
+		changes = append(changes, c)
+	}
+
+	// Files of templates that no longer exist are left to the user.
+	for _, f := range m.Files {
+		if _, ok := next.Lookup(f.Path); ok || f.TemplateSHA256 == "" {
+			continue
+		}
+		changes = append(changes, upgradeChange{file: f, action: upgradeObsolete})
+	}
+
+	return changes, nil
+}
+
+// applyUpgrade writes the changes to the project in dir and records the new
+// templates in its manifest m.
+func applyUpgrade(dir string, m *manifest.Manifest, changes []upgradeChange) error {
+	for _, c := range changes {
+		dst := filepath.Join(dir, filepath.FromSlash(c.file.Path))
+
+		if c.contents != nil {
+			err := os.MkdirAll(filepath.Dir(dst), fsys.DirPerm)
+			if err != nil {
+				return err
+			}
+
+			// Existing files keep their permissions.
+			err = os.WriteFile(dst, c.contents, fsys.Mode(c.file.Path, c.contents))
+			if err != nil {
+				return err
+			}
+		}
+
+		if c.rej != nil {
+			err := os.WriteFile(dst+".rej", c.rej, fsys.Mode(c.file.Path+".rej", c.rej))
+			if err != nil {
+				return err
+			}
+		}
+
+		// Rejected changes aren't in the file, so the previous base and
+		// checksum are kept for the next upgrade to offer them again.
+		if c.action == upgradeDeleted || c.action == upgradeObsolete || c.action == upgradeYours || c.rej != nil {
+			continue
+		}
+
+		// The new template's output is what later upgrades merge against.
+		err := manifest.WriteBase(fsys.OS(dir), c.file.Path, c.theirs)
+		if err != nil {
+			return err
+		}
+		m.Add(c.file)
+	}
+
+	m.Generator.Version = generatorVersion()
+	m.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+
+	return m.Write(dir)
+}
+
+func printUpgradeChange(c upgradeChange) {
+	label := fmt.Sprintf("%-9s", c.action)
+	switch c.action {
+	case upgradeUpdated, upgradeMerged, upgradeAdded:
+		label = color.GreenString(label)
+	case upgradeConflict:
+		label = color.RedString(label)
+	default:
+		label = color.YellowString(label)
+	}
+
+	suffix := ""
+	if c.rej != nil {
+		suffix = " (rejected changes in " + c.file.Path + ".rej)"
+	}
+	fmt.Fprintf(color.Output, "  %s %s%s\n", label, c.file.Path, suffix)
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"create-go-app.dev/fsys"
+	"create-go-app.dev/manifest"
+)
+
+// generatedProject writes the files of a project as the generator left
+// them, with its manifest and base, into a temporary directory and then the
+// user's edits over them.
+func generatedProject(t *testing.T, generated map[string]string, edits map[string]string) (string, *manifest.Manifest) {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "project")
+	writeFiles(t, dir, generated)
+
+	m := &manifest.Manifest{Generator: manifest.Generator{Version: "v1.0.0"}}
+	for _, path := range slices.Sorted(maps.Keys(generated)) {
+		m.Add(manifest.File{Path: path, TemplateSHA256: "template"})
+	}
+	err := m.Hash(os.DirFS(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.SaveBase(fsys.OS(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.Write(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFiles(t, dir, edits)
+	return dir, m
+}
+
+// rendered writes the files of the project rendered with newer templates
+// into a temporary directory.
+func rendered(t *testing.T, files map[string]string) (string, *manifest.Manifest) {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "next")
+	writeFiles(t, dir, files)
+
+	next := &manifest.Manifest{}
+	for _, path := range slices.Sorted(maps.Keys(files)) {
+		next.Add(manifest.File{Path: path, TemplateSHA256: "template"})
+	}
+	err := next.Hash(os.DirFS(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, next
+}
+
+func TestUpgradeChanges(t *testing.T) {
+	generated := map[string]string{
+		"main.go":   "package main\n\nfunc main() {\n}\n",
+		".env":      "GO_HOST=go\nGO_PORT=3000\n",
+		"README.md": "# my-app\n",
+	}
+
+	tests := []struct {
+		title string
+		edits map[string]string
+		// Deleted by the user.
+		deleted []string
+		next    map[string]string
+		want    map[string]string
+	}{
+		{
+			title: "Unchanged templates",
+			next:  generated,
+			want:  map[string]string{"main.go": upgradeUnchanged, ".env": upgradeUnchanged, "README.md": upgradeUnchanged},
+		},
+		{
+			title: "Edited files, unchanged templates",
+			edits: map[string]string{"README.md": "# My app\n", ".env": "GO_HOST=go\nGO_PORT=8080\n"},
+			next:  generated,
+			want:  map[string]string{"main.go": upgradeUnchanged, ".env": upgradeKept, "README.md": upgradeKept},
+		},
+		{
+			title: "Changed templates",
+			next: map[string]string{
+				"main.go":   "package main\n\nfunc main() {\n\trun()\n}\n",
+				".env":      "GO_HOST=go\nGO_PORT=3000\nGO_ENV=development\n",
+				"README.md": "# my-app\n",
+			},
+			want: map[string]string{"main.go": upgradeUpdated, ".env": upgradeUpdated, "README.md": upgradeUnchanged},
+		},
+		{
+			title: "Edited files, changed templates",
+			edits: map[string]string{
+				"main.go": "// Package main runs my-app.\npackage main\n\nfunc main() {\n}\n",
+				".env":    "GO_HOST=go\nGO_PORT=8080\n",
+			},
+			next: map[string]string{
+				"main.go":   "package main\n\nfunc main() {\n\trun()\n}\n",
+				".env":      "GO_HOST=go\nGO_PORT=3000\nGO_ENV=development\n",
+				"README.md": "# my-app\n",
+			},
+			// The .env has no base to merge with.
+			want: map[string]string{"main.go": upgradeMerged, ".env": upgradeConflict, "README.md": upgradeUnchanged},
+		},
+		{
+			title:   "New and deleted files",
+			deleted: []string{"README.md"},
+			next: map[string]string{
+				"main.go":   generated["main.go"],
+				".env":      generated[".env"],
+				"README.md": "# my-app\n\nRun it with docker compose up.\n",
+				"go.mod":    "module my-app\n",
+			},
+			want: map[string]string{"main.go": upgradeUnchanged, ".env": upgradeUnchanged, "README.md": upgradeDeleted, "go.mod": upgradeAdded},
+		},
+		{
+			title: "Removed template",
+			next:  map[string]string{"main.go": generated["main.go"], ".env": generated[".env"]},
+			want:  map[string]string{"main.go": upgradeUnchanged, ".env": upgradeUnchanged, "README.md": upgradeObsolete},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			dir, m := generatedProject(t, generated, tt.edits)
+			for _, path := range tt.deleted {
+				err := os.Remove(filepath.Join(dir, path))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			tmp, next := rendered(t, tt.next)
+
+			changes, err := upgradeChanges(dir, m, next, tmp, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := map[string]string{}
+			for _, c := range changes {
+				got[c.file.Path] = c.action
+				if (c.action == upgradeKept || c.action == upgradeUnchanged) && c.contents != nil {
+					t.Errorf("%s is %s but rewritten:\n%s", c.file.Path, c.action, c.contents)
+				}
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("actions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyUpgradeRejected(t *testing.T) {
+	generated := map[string]string{"main.go": "package main\n\nfunc main() {\n}\n"}
+	dir, m := generatedProject(t, generated, map[string]string{"main.go": "package main\n\nfunc main() {\n\tserve()\n}\n"})
+	tmp, next := rendered(t, map[string]string{"main.go": "package main\n\nfunc main() {\n\trun()\n}\n"})
+
+	for i := range 2 {
+		changes, err := upgradeChanges(dir, m, next, tmp, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(changes) != 1 || changes[0].action != upgradeConflict || changes[0].rej == nil {
+			t.Fatalf("upgrade %d: changes = %+v, want main.go rejected", i+1, changes)
+		}
+
+		err = applyUpgrade(dir, m, changes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The file is left as the user edited it.
+		b, err := os.ReadFile(filepath.Join(dir, "main.go"))
+		if err != nil || string(b) != "package main\n\nfunc main() {\n\tserve()\n}\n" {
+			t.Errorf("upgrade %d: main.go = %q, %v", i+1, b, err)
+		}
+	}
+}
+
+func TestUpgradeAfterSkip(t *testing.T) {
+	// The app was generated into a directory with a LICENSE, which was kept
+	// with -on-conflict=skip and so isn't in the manifest.
+	generated := map[string]string{"main.go": "package main\n"}
+	dir, m := generatedProject(t, generated, map[string]string{"LICENSE": "Copyright Jane Doe\n"})
+	tmp, next := rendered(t, map[string]string{"main.go": "package main\n", "LICENSE": "MIT License\n"})
+
+	changes, err := upgradeChanges(dir, m, next, tmp, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, c := range changes {
+		got[c.file.Path] = c.action
+	}
+	want := map[string]string{"main.go": upgradeUnchanged, "LICENSE": upgradeYours}
+	if !maps.Equal(got, want) {
+		t.Fatalf("actions = %v, want %v", got, want)
+	}
+
+	err = applyUpgrade(dir, m, changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "LICENSE"))
+	if err != nil || string(b) != "Copyright Jane Doe\n" {
+		t.Errorf("LICENSE = %q, %v, want it left alone", b, err)
+	}
+	if _, err := manifest.Base(dir, "LICENSE"); !os.IsNotExist(err) {
+		t.Errorf("LICENSE has a base: %v", err)
+	}
+	m, err = manifest.Read(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.Lookup("LICENSE"); ok {
+		t.Error("LICENSE is in the manifest")
+	}
+}
@@ -0,0 +1,182 @@
+package wizard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"create-go-app.dev/component"
+	"create-go-app.dev/prompt"
+)
+
+// option is an answer to pick from.
+type option struct {
+	name    string
+	summary string
+}
+
+// ui asks the wizard's questions. Every question returns errBack when the
+// user goes back, and check, when not nil, returns why an answer is invalid
+// so the question is asked again.
+type ui interface {
+	// choose returns the index of the option picked, def by default.
+	choose(ctx context.Context, q string, options []option, def int, check func(i int) error) (int, error)
+	// multi returns which options are picked, selected by default.
+	multi(ctx context.Context, q string, options []option, selected []bool, check func(selected []bool) error) ([]bool, error)
+	// input returns a line of text, def by default.
+	input(ctx context.Context, q string, def string, check func(s string) error) (string, error)
+	// summary shows the answers before the final question.
+	summary(lines []string)
+}
+
+// back is the answer that goes back a step at a line prompt.
+const back = "<"
+
+// lineUI asks every question as a line prompt, e.g. when stdin is piped.
+type lineUI struct{}
+
+// line reads an answer, failing when stdin is closed.
+func (lineUI) line(ctx context.Context, q string, label string) (string, error) {
+	answer, err := prompt.LineContext(ctx, label)
+	if errors.Is(err, prompt.ErrNoInput) {
+		return "", fmt.Errorf("create-go-app: no answer to '%s' on stdin, pass it as a flag along with -yes", q)
+	}
+	return answer, err
+}
+
+func (u lineUI) choose(ctx context.Context, q string, options []option, def int, check func(i int) error) (int, error) {
+	fmt.Fprintf(prompt.Output, "%s:\n", q)
+	for i, o := range options {
+		fmt.Fprintln(prompt.Output, strings.TrimRight(fmt.Sprintf("  %d. %-12s %s", i+1, o.name, o.summary), " "))
+	}
+
+	for {
+		answer, err := u.line(ctx, q, fmt.Sprintf("Choose a number or name (default %s, '%s' to go back): ", options[def].name, back))
+		if err != nil {
+			return 0, err
+		}
+
+		i := def
+		switch {
+		case answer == back:
+			return 0, errBack
+		case answer != "":
+			i = index(options, answer)
+		}
+		if i < 0 {
+			fmt.Fprintf(prompt.Output, "'%s' isn't one of the choices.\n", answer)
+			continue
+		}
+
+		if check != nil {
+			if err := check(i); err != nil {
+				fmt.Fprintln(prompt.Output, err)
+				continue
+			}
+		}
+		return i, nil
+	}
+}
+
+func (u lineUI) multi(ctx context.Context, q string, options []option, selected []bool, check func(selected []bool) error) ([]bool, error) {
+	fmt.Fprintf(prompt.Output, "%s:\n", q)
+	var current []string
+	for i, o := range options {
+		mark := " "
+		if selected[i] {
+			mark = "x"
+			current = append(current, o.name)
+		}
+		fmt.Fprintf(prompt.Output, "  %d. [%s] %-12s %s\n", i+1, mark, o.name, o.summary)
+	}
+	if len(current) == 0 {
+		current = []string{"none"}
+	}
+
+	for {
+		answer, err := u.line(ctx, q, fmt.Sprintf("Select, e.g. '1,2' or '%s', 'none' for none (default %s, '%s' to go back): ", options[0].name, strings.Join(current, ","), back))
+		if err != nil {
+			return nil, err
+		}
+		if answer == back {
+			return nil, errBack
+		}
+
+		picked := selected
+		if answer != "" {
+			picked = make([]bool, len(options))
+			for _, name := range component.Parse(answer) {
+				i := index(options, name)
+				if i < 0 {
+					picked = nil
+					fmt.Fprintf(prompt.Output, "'%s' isn't one of the choices.\n", name)
+					break
+				}
+				picked[i] = true
+			}
+		}
+		if picked == nil {
+			continue
+		}
+
+		if check != nil {
+			if err := check(picked); err != nil {
+				fmt.Fprintln(prompt.Output, err)
+				continue
+			}
+		}
+		return picked, nil
+	}
+}
+
+func (u lineUI) input(ctx context.Context, q string, def string, check func(s string) error) (string, error) {
+	label := q
+	if def != "" {
+		label += fmt.Sprintf(" [%s]", def)
+	}
+	label += fmt.Sprintf(" ('%s' to go back): ", back)
+
+	for {
+		answer, err := u.line(ctx, q, label)
+		if err != nil {
+			return "", err
+		}
+		if answer == back {
+			return "", errBack
+		}
+		if answer == "" {
+			answer = def
+		}
+
+		if check != nil {
+			if err := check(answer); err != nil {
+				fmt.Fprintln(prompt.Output, err)
+				continue
+			}
+		}
+		return answer, nil
+	}
+}
+
+func (lineUI) summary(lines []string) {
+	fmt.Fprintln(prompt.Output, "Summary:")
+	for _, l := range lines {
+		fmt.Fprintf(prompt.Output, "  %s\n", l)
+	}
+}
+
+// index returns the index of the option answer names, by number or name,
+// or -1.
+func index(options []option, answer string) int {
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+		return n - 1
+	}
+	for i, o := range options {
+		if strings.EqualFold(o.name, answer) {
+			return i
+		}
+	}
+	return -1
+}
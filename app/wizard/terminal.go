@@ -0,0 +1,385 @@
+package wizard
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"create-go-app.dev/prompt"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// key is a key the terminal UI reacts to.
+type key int
+
+const (
+	keyOther key = iota
+	keyRune
+	keyUp
+	keyDown
+	keyLeft
+	keyEnter
+	keyBackspace
+	keyClear
+	keyCancel
+)
+
+// terminal asks with menus picked with the arrow keys, each question redrawn
+// in place until it is answered and then collapsed to a line.
+type terminal struct {
+	in  *bufio.Reader
+	out io.Writer
+	// raw puts the terminal in raw mode and returns a func restoring it, nil
+	// when the terminal is faked.
+	raw func() (func(), error)
+	// Lines drawn for the current question, the cursor is on the last one.
+	drawn int
+	// Lines printed above the current question, e.g. the summary.
+	pending int
+	// Lines each answered question left, to erase them when going back.
+	answered []int
+}
+
+func newTerminal(in *os.File, out *os.File) *terminal {
+	fd := int(in.Fd())
+	return &terminal{
+		// Keys typed ahead are left for the prompts after the wizard.
+		in:  prompt.Input,
+		out: out,
+		raw: func() (func(), error) {
+			state, err := term.MakeRaw(fd)
+			if err != nil {
+				return nil, err
+			}
+			return func() { term.Restore(fd, state) }, nil
+		},
+	}
+}
+
+// start prepares the terminal for a question and returns a func undoing it.
+func (t *terminal) start(ctx context.Context, cursor bool) (func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	restore := func() {}
+	if t.raw != nil {
+		r, err := t.raw()
+		if err != nil {
+			return nil, err
+		}
+		restore = r
+	}
+
+	if cursor {
+		return restore, nil
+	}
+
+	fmt.Fprint(t.out, "\x1b[?25l")
+	return func() {
+		fmt.Fprint(t.out, "\x1b[?25h")
+		restore()
+	}, nil
+}
+
+// key reads the next key press.
+func (t *terminal) key() (key, rune, error) {
+	r, _, err := t.in.ReadRune()
+	if err != nil {
+		return keyOther, 0, err
+	}
+
+	switch r {
+	case '\r', '\n':
+		return keyEnter, r, nil
+	case 0x7f, 0x08:
+		return keyBackspace, r, nil
+	case 0x15:
+		return keyClear, r, nil
+	case 0x03, 0x04:
+		return keyCancel, r, nil
+	case 0x1b:
+		// Arrow keys are 'ESC [ A' or 'ESC O A'.
+		if b, err := t.in.ReadByte(); err != nil || (b != '[' && b != 'O') {
+			return keyOther, r, err
+		}
+		b, err := t.in.ReadByte()
+		switch {
+		case err != nil:
+			return keyOther, r, err
+		case b == 'A':
+			return keyUp, r, nil
+		case b == 'B':
+			return keyDown, r, nil
+		case b == 'D':
+			return keyLeft, r, nil
+		}
+		return keyOther, r, nil
+	}
+
+	if unicode.IsPrint(r) {
+		return keyRune, r, nil
+	}
+	return keyOther, r, nil
+}
+
+// draw replaces the lines of the current question with lines.
+func (t *terminal) draw(lines []string) {
+	var b strings.Builder
+	t.up(&b, t.drawn-1)
+	b.WriteString(strings.Join(lines, "\r\n"))
+	t.drawn = len(lines)
+	io.WriteString(t.out, b.String())
+}
+
+// up moves the cursor to the start of the nth line above and clears the
+// screen below.
+func (t *terminal) up(b *strings.Builder, n int) {
+	if n > 0 {
+		fmt.Fprintf(b, "\x1b[%dA", n)
+	}
+	b.WriteString("\r\x1b[J")
+}
+
+// done collapses the current question to its answer.
+func (t *terminal) done(q string, answer string) {
+	t.draw([]string{fmt.Sprintf("%s %s %s", color.GreenString("✔"), q, color.CyanString(answer))})
+	io.WriteString(t.out, "\r\n")
+	t.answered = append(t.answered, t.pending+1)
+	t.drawn, t.pending = 0, 0
+}
+
+// back erases the current question and the previous answer, which is asked
+// again.
+func (t *terminal) back() error {
+	n := t.drawn - 1 + t.pending
+	if len(t.answered) > 0 {
+		n += t.answered[len(t.answered)-1]
+		t.answered = t.answered[:len(t.answered)-1]
+	}
+
+	var b strings.Builder
+	t.up(&b, n)
+	io.WriteString(t.out, b.String())
+	t.drawn, t.pending = 0, 0
+	return errBack
+}
+
+// cancel erases the current question.
+func (t *terminal) cancel() error {
+	var b strings.Builder
+	t.up(&b, t.drawn-1)
+	io.WriteString(t.out, b.String())
+	t.drawn = 0
+	return ErrCanceled
+}
+
+func question(q string, hint string) string {
+	return fmt.Sprintf("%s %s %s", color.CyanString("?"), color.New(color.Bold).Sprint(q), color.New(color.Faint).Sprint(hint))
+}
+
+func problem(err error) string {
+	return "  " + color.RedString(err.Error())
+}
+
+func (t *terminal) choose(ctx context.Context, q string, options []option, def int, check func(i int) error) (int, error) {
+	restore, err := t.start(ctx, false)
+	if err != nil {
+		return 0, err
+	}
+	defer restore()
+
+	cur := def
+	var msg error
+	for {
+		lines := []string{question(q, "↑/↓ move, enter select, ← back")}
+		for i, o := range options {
+			pointer, name := "  ", fmt.Sprintf("%-12s", o.name)
+			if i == cur {
+				pointer, name = color.CyanString("❯ "), color.CyanString(name)
+			}
+			lines = append(lines, "  "+pointer+name+" "+o.summary)
+		}
+		if msg != nil {
+			lines = append(lines, problem(msg))
+		}
+		t.draw(lines)
+
+		k, _, err := t.key()
+		if err != nil {
+			return 0, err
+		}
+
+		msg = nil
+		switch k {
+		case keyUp:
+			cur = (cur + len(options) - 1) % len(options)
+		case keyDown:
+			cur = (cur + 1) % len(options)
+		case keyLeft:
+			return 0, t.back()
+		case keyCancel:
+			return 0, t.cancel()
+		case keyEnter:
+			if check != nil {
+				if msg = check(cur); msg != nil {
+					continue
+				}
+			}
+			t.done(q, options[cur].name)
+			return cur, nil
+		}
+	}
+}
+
+func (t *terminal) multi(ctx context.Context, q string, options []option, selected []bool, check func(selected []bool) error) ([]bool, error) {
+	restore, err := t.start(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	defer restore()
+
+	picked := append([]bool(nil), selected...)
+	cur := 0
+	var msg error
+	for {
+		lines := []string{question(q, "↑/↓ move, space toggle, a all, enter confirm, ← back")}
+		for i, o := range options {
+			pointer, box, name := "  ", "[ ]", fmt.Sprintf("%-12s", o.name)
+			if picked[i] {
+				box = color.GreenString("[x]")
+			}
+			if i == cur {
+				pointer, name = color.CyanString("❯ "), color.CyanString(name)
+			}
+			lines = append(lines, "  "+pointer+box+" "+name+" "+o.summary)
+		}
+		if msg != nil {
+			lines = append(lines, problem(msg))
+		}
+		t.draw(lines)
+
+		k, r, err := t.key()
+		if err != nil {
+			return nil, err
+		}
+
+		msg = nil
+		switch {
+		case k == keyUp:
+			cur = (cur + len(options) - 1) % len(options)
+		case k == keyDown:
+			cur = (cur + 1) % len(options)
+		case k == keyRune && r == ' ':
+			picked[cur] = !picked[cur]
+		case k == keyRune && r == 'a':
+			all := !allTrue(picked)
+			for i := range picked {
+				picked[i] = all
+			}
+		case k == keyLeft:
+			return nil, t.back()
+		case k == keyCancel:
+			return nil, t.cancel()
+		case k == keyEnter:
+			if check != nil {
+				if msg = check(picked); msg != nil {
+					continue
+				}
+			}
+
+			var names []string
+			for i, ok := range picked {
+				if ok {
+					names = append(names, options[i].name)
+				}
+			}
+			if len(names) == 0 {
+				names = []string{"none"}
+			}
+			t.done(q, strings.Join(names, ", "))
+			return picked, nil
+		}
+	}
+}
+
+func (t *terminal) input(ctx context.Context, q string, def string, check func(s string) error) (string, error) {
+	restore, err := t.start(ctx, true)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	hint := "enter confirm, ← back"
+	if def != "" {
+		hint = fmt.Sprintf("default %s, %s", def, hint)
+	}
+
+	var buf []rune
+	var msg error
+	for {
+		lines := []string{question(q, hint)}
+		if msg != nil {
+			lines = append(lines, problem(msg))
+		}
+		// The answer is drawn last, so the cursor follows it.
+		lines = append(lines, color.CyanString("❯ ")+string(buf))
+		t.draw(lines)
+
+		k, r, err := t.key()
+		if err != nil {
+			return "", err
+		}
+
+		msg = nil
+		switch k {
+		case keyRune:
+			buf = append(buf, r)
+		case keyBackspace:
+			if len(buf) > 0 {
+				buf = buf[:len(buf)-1]
+			}
+		case keyClear:
+			buf = nil
+		case keyLeft:
+			return "", t.back()
+		case keyCancel:
+			return "", t.cancel()
+		case keyEnter:
+			answer := strings.TrimSpace(string(buf))
+			if answer == "" {
+				answer = def
+			}
+			if check != nil {
+				if msg = check(answer); msg != nil {
+					continue
+				}
+			}
+			t.done(q, answer)
+			return answer, nil
+		}
+	}
+}
+
+func (t *terminal) summary(lines []string) {
+	fmt.Fprintf(t.out, "%s\r\n", color.New(color.Bold).Sprint("Summary"))
+	for _, l := range lines {
+		fmt.Fprintf(t.out, "  %s\r\n", l)
+	}
+	t.pending += len(lines) + 1
+}
+
+func allTrue(picked []bool) bool {
+	for _, ok := range picked {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,412 @@
+// Package wizard asks for the options of a new project one step at a time,
+// with defaults, going back to earlier steps and a summary to confirm. On a
+// terminal options are picked with the arrow keys, otherwise every step is a
+// line prompt.
+package wizard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"create-go-app.dev/component"
+	"create-go-app.dev/tmpl"
+
+	"golang.org/x/term"
+)
+
+// Steps, to mark the ones answered already in Wizard.Given.
+const (
+	StepType       = "type"
+	StepModule     = "module"
+	StepComponents = "components"
+	StepDatabase   = "database"
+	StepPorts      = "ports"
+	StepLicense    = "license"
+)
+
+// Database is the component offered as the database instead of with the
+// other components.
+const Database = "postgres"
+
+// ErrCanceled is returned when the user cancels the wizard.
+var ErrCanceled = errors.New("create-go-app: canceled")
+
+// errBack is returned by a question when the user goes back a step.
+var errBack = errors.New("back")
+
+// Type is a project type the user can pick.
+type Type struct {
+	Name    string
+	Summary string
+	// Optional components, Database among them when the type has one.
+	Components component.Set
+	// Whether the app publishes ports on the host.
+	Ports bool
+}
+
+// Answers are the options the wizard asks for. Their values when the wizard
+// starts are the defaults.
+type Answers struct {
+	Type       string
+	Module     string
+	Components []string
+	Ports      tmpl.Ports
+	License    string
+}
+
+// Wizard asks for the options of a new project.
+type Wizard struct {
+	Types    []Type
+	Licenses []string
+	// Steps answered already, e.g. with flags, which aren't asked.
+	Given map[string]bool
+	// CheckModule returns why a module path is invalid.
+	CheckModule func(path string) error
+
+	ui ui
+}
+
+// New returns a wizard asking on in and out, with menus when both are
+// terminals and line prompts otherwise.
+func New(in *os.File, out *os.File) *Wizard {
+	if term.IsTerminal(int(in.Fd())) && term.IsTerminal(int(out.Fd())) {
+		return &Wizard{ui: newTerminal(in, out)}
+	}
+	return &Wizard{ui: lineUI{}}
+}
+
+// step is a question of the wizard.
+type step struct {
+	name string
+	// Whether the step applies to the answers so far, always when nil.
+	when func(a *Answers) bool
+	ask  func(ctx context.Context, a *Answers) error
+}
+
+// Run asks the steps that aren't given and stores the answers in a. Going
+// back from a step returns to the previous one asked. It returns
+// ErrCanceled when the user cancels at the summary or with Ctrl-C.
+func (w *Wizard) Run(ctx context.Context, a *Answers) error {
+	steps := w.steps()
+
+	// Indexes of the steps asked, to go back to.
+	var asked []int
+	for i := 0; i < len(steps); {
+		s := steps[i]
+		if w.Given[s.name] || (s.when != nil && !s.when(a)) {
+			i++
+			continue
+		}
+
+		err := s.ask(ctx, a)
+		if errors.Is(err, errBack) {
+			// The first step is asked again.
+			if len(asked) > 0 {
+				i, asked = asked[len(asked)-1], asked[:len(asked)-1]
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		asked = append(asked, i)
+		i++
+	}
+
+	return nil
+}
+
+func (w *Wizard) steps() []step {
+	steps := []step{
+		{name: StepType, ask: w.askType},
+		{name: StepModule, ask: w.askModule},
+		{name: StepComponents, when: w.hasComponents, ask: w.askComponents},
+		{name: StepDatabase, when: w.hasDatabase, ask: w.askDatabase},
+	}
+
+	for _, p := range ports {
+		steps = append(steps, step{
+			name: StepPorts,
+			when: func(a *Answers) bool {
+				return w.typ(a).Ports && (p.component == "" || slices.Contains(a.Components, p.component))
+			},
+			ask: func(ctx context.Context, a *Answers) error {
+				return w.askPort(ctx, a, p)
+			},
+		})
+	}
+
+	return append(steps,
+		step{name: StepLicense, ask: w.askLicense},
+		step{name: "summary", ask: w.confirm},
+	)
+}
+
+// typ returns the type answered so far.
+func (w *Wizard) typ(a *Answers) Type {
+	i := slices.IndexFunc(w.Types, func(t Type) bool { return t.Name == a.Type })
+	if i < 0 {
+		return Type{Name: a.Type}
+	}
+	return w.Types[i]
+}
+
+func (w *Wizard) askType(ctx context.Context, a *Answers) error {
+	var options []option
+	for _, t := range w.Types {
+		options = append(options, option{name: t.Name, summary: t.Summary})
+	}
+
+	def := max(slices.IndexFunc(w.Types, func(t Type) bool { return t.Name == a.Type }), 0)
+	i, err := w.ui.choose(ctx, "Project type", options, def, nil)
+	if err != nil {
+		return err
+	}
+
+	// Another type starts with all of its components.
+	if w.Types[i].Name != a.Type {
+		a.Type = w.Types[i].Name
+		a.Components, _ = w.Types[i].Components.Select(nil, nil)
+	}
+	return nil
+}
+
+func (w *Wizard) askModule(ctx context.Context, a *Answers) error {
+	module, err := w.ui.input(ctx, "Module path, e.g. github.com/username/my-app", a.Module, func(s string) error {
+		if s == "" {
+			return errors.New("a module path is required")
+		}
+		if w.CheckModule == nil {
+			return nil
+		}
+		return w.CheckModule(s)
+	})
+	if err != nil {
+		return err
+	}
+
+	a.Module = module
+	return nil
+}
+
+// optional returns the components asked for at the components step.
+func (w *Wizard) optional(a *Answers) component.Set {
+	return slices.DeleteFunc(slices.Clone(w.typ(a).Components), func(c component.Component) bool {
+		return c.Name == Database
+	})
+}
+
+func (w *Wizard) hasComponents(a *Answers) bool {
+	return len(w.optional(a)) > 0
+}
+
+func (w *Wizard) hasDatabase(a *Answers) bool {
+	_, ok := w.typ(a).Components.Lookup(Database)
+	return ok
+}
+
+func (w *Wizard) askComponents(ctx context.Context, a *Answers) error {
+	set := w.optional(a)
+
+	var options []option
+	selected := make([]bool, len(set))
+	for i, c := range set {
+		options = append(options, option{name: c.Name, summary: c.Summary})
+		selected[i] = slices.Contains(a.Components, c.Name)
+	}
+
+	// The database is chosen at its own step.
+	names := func(selected []bool) []string {
+		names := []string{}
+		for i, ok := range selected {
+			if ok {
+				names = append(names, set[i].Name)
+			}
+		}
+		if slices.Contains(a.Components, Database) {
+			names = append(names, Database)
+		}
+		return w.order(a, names)
+	}
+
+	selected, err := w.ui.multi(ctx, "Components", options, selected, func(selected []bool) error {
+		_, err := w.typ(a).Components.Select(names(selected), nil)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	a.Components = names(selected)
+	return nil
+}
+
+// order returns the names in the order of the type's components.
+func (w *Wizard) order(a *Answers, names []string) []string {
+	ordered := []string{}
+	for _, c := range w.typ(a).Components {
+		if slices.Contains(names, c.Name) {
+			ordered = append(ordered, c.Name)
+		}
+	}
+	return ordered
+}
+
+func (w *Wizard) askDatabase(ctx context.Context, a *Answers) error {
+	db, _ := w.typ(a).Components.Lookup(Database)
+	options := []option{
+		{name: db.Name, summary: db.Summary},
+		{name: "none", summary: "No database"},
+	}
+
+	def := 1
+	if slices.Contains(a.Components, Database) {
+		def = 0
+	}
+
+	others := slices.DeleteFunc(slices.Clone(a.Components), func(name string) bool { return name == Database })
+	components := func(i int) []string {
+		if i == 0 {
+			return w.order(a, append(slices.Clone(others), Database))
+		}
+		return w.order(a, others)
+	}
+
+	i, err := w.ui.choose(ctx, "Database", options, def, func(i int) error {
+		_, err := w.typ(a).Components.Select(components(i), nil)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	a.Components = components(i)
+	return nil
+}
+
+// port is a host port the wizard asks for.
+type port struct {
+	label string
+	// Component publishing the port, empty for the Go server.
+	component string
+	field     func(p *tmpl.Ports) *int
+}
+
+var ports = []port{
+	{label: "Go server", field: func(p *tmpl.Ports) *int { return &p.Go }},
+	{label: "Postgres", component: "postgres", field: func(p *tmpl.Ports) *int { return &p.Postgres }},
+	{label: "Redis", component: "redis", field: func(p *tmpl.Ports) *int { return &p.Redis }},
+	{label: "Swagger UI", component: "swagger", field: func(p *tmpl.Ports) *int { return &p.SwaggerUI }},
+	{label: "Swagger editor", component: "swagger", field: func(p *tmpl.Ports) *int { return &p.SwaggerEditor }},
+	{label: "Node client", component: "node", field: func(p *tmpl.Ports) *int { return &p.Node }},
+}
+
+func (w *Wizard) askPort(ctx context.Context, a *Answers, p port) error {
+	value, err := w.ui.input(ctx, p.label+" port", strconv.Itoa(*p.field(&a.Ports)), func(s string) error {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("'%s' isn't a port between 1 and 65535", s)
+		}
+
+		// Ports of the services that are published have to differ.
+		for _, other := range ports {
+			if other.label == p.label || (other.component != "" && !slices.Contains(a.Components, other.component)) {
+				continue
+			}
+			if *other.field(&a.Ports) == n {
+				return fmt.Errorf("port %d is already used by %s", n, other.label)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*p.field(&a.Ports), _ = strconv.Atoi(value)
+	return nil
+}
+
+func (w *Wizard) askLicense(ctx context.Context, a *Answers) error {
+	var options []option
+	for _, l := range w.Licenses {
+		options = append(options, option{name: l})
+	}
+
+	def := max(slices.Index(w.Licenses, a.License), 0)
+	i, err := w.ui.choose(ctx, "License", options, def, nil)
+	if err != nil {
+		return err
+	}
+
+	a.License = w.Licenses[i]
+	return nil
+}
+
+// confirm shows the answers and asks whether to create the app.
+func (w *Wizard) confirm(ctx context.Context, a *Answers) error {
+	w.ui.summary(w.Summary(a))
+
+	i, err := w.ui.choose(ctx, "Create the app?", []option{
+		{name: "yes", summary: "Create the app"},
+		{name: "back", summary: "Change an answer"},
+		{name: "cancel", summary: "Quit without creating anything"},
+	}, 0, nil)
+	switch {
+	case err != nil:
+		return err
+	case i == 1:
+		return errBack
+	case i == 2:
+		return ErrCanceled
+	}
+	return nil
+}
+
+// Summary returns the answers as lines of 'label: value'.
+func (w *Wizard) Summary(a *Answers) []string {
+	t := w.typ(a)
+	lines := []string{
+		"Type: " + a.Type,
+		"Module: " + a.Module,
+	}
+
+	if w.hasComponents(a) {
+		var names []string
+		for _, c := range w.optional(a) {
+			if slices.Contains(a.Components, c.Name) {
+				names = append(names, c.Name)
+			}
+		}
+		if len(names) == 0 {
+			names = []string{"none"}
+		}
+		lines = append(lines, "Components: "+strings.Join(names, ", "))
+	}
+
+	if w.hasDatabase(a) {
+		db := "none"
+		if slices.Contains(a.Components, Database) {
+			db = Database
+		}
+		lines = append(lines, "Database: "+db)
+	}
+
+	if t.Ports {
+		var published []string
+		for _, p := range ports {
+			if p.component == "" || slices.Contains(a.Components, p.component) {
+				published = append(published, fmt.Sprintf("%s %d", p.label, *p.field(&a.Ports)))
+			}
+		}
+		lines = append(lines, "Ports: "+strings.Join(published, ", "))
+	}
+
+	return append(lines, "License: "+a.License)
+}
@@ -0,0 +1,311 @@
+package wizard
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"create-go-app.dev/component"
+	"create-go-app.dev/prompt"
+	"create-go-app.dev/tmpl"
+)
+
+func testWizard(u ui) *Wizard {
+	return &Wizard{
+		Types: []Type{
+			{
+				Name:  "http",
+				Ports: true,
+				Components: component.Set{
+					{Name: "postgres"},
+					{Name: "redis"},
+					{Name: "node"},
+					{Name: "playwright", Requires: []string{"node"}},
+				},
+			},
+			{Name: "cli"},
+		},
+		Licenses: []string{"MIT", "Apache-2.0", "none"},
+		CheckModule: func(path string) error {
+			if strings.Contains(path, " ") {
+				return errors.New("invalid module path")
+			}
+			return nil
+		},
+		ui: u,
+	}
+}
+
+func testAnswers() *Answers {
+	return &Answers{
+		Type:       "http",
+		Components: []string{"postgres", "redis", "node", "playwright"},
+		Ports:      tmpl.DefaultPorts(),
+		License:    "MIT",
+	}
+}
+
+func TestRunLine(t *testing.T) {
+	ports := tmpl.DefaultPorts()
+	ports.Go, ports.Node = 8080, 3000
+
+	tests := []struct {
+		title string
+		given map[string]bool
+		// One answer per line.
+		input   string
+		want    Answers
+		wantErr error
+	}{
+		{
+			title: "Defaults",
+			input: "\nexample.com/app\n\n\n\n\n\n\n\n\n",
+			want: Answers{
+				Type:       "http",
+				Module:     "example.com/app",
+				Components: []string{"postgres", "redis", "node", "playwright"},
+				Ports:      tmpl.DefaultPorts(),
+				License:    "MIT",
+			},
+		},
+		{
+			title: "A type without components or ports",
+			input: "cli\nexample.com/cli\n2\nyes\n",
+			want: Answers{
+				Type:    "cli",
+				Module:  "example.com/cli",
+				Ports:   tmpl.DefaultPorts(),
+				License: "Apache-2.0",
+			},
+		},
+		{
+			title: "Back",
+			// The license goes back to the module, which goes back to the
+			// type.
+			input: "cli\nexample.com/a\n<\n<\nhttp\n\nnone\nnone\n\n\n\n",
+			want: Answers{
+				Type:       "http",
+				Module:     "example.com/a",
+				Components: []string{},
+				Ports:      tmpl.DefaultPorts(),
+				License:    "MIT",
+			},
+		},
+		{
+			title: "Back from the summary",
+			input: "cli\nexample.com/a\n\nback\nnone\n\n",
+			want: Answers{
+				Type:    "cli",
+				Module:  "example.com/a",
+				Ports:   tmpl.DefaultPorts(),
+				License: "none",
+			},
+		},
+		{
+			title: "Invalid answers are asked again",
+			// An empty module path, an invalid one, a component missing its
+			// requirement, a port out of range and one already used.
+			input: "http\n\nmy app\nexample.com/app\n3\n2,3\nnone\n0\n8080\n8080\n3000\nmit\n\n",
+			want: Answers{
+				Type:       "http",
+				Module:     "example.com/app",
+				Components: []string{"node", "playwright"},
+				Ports:      ports,
+				License:    "MIT",
+			},
+		},
+		{
+			title: "Given steps aren't asked",
+			given: map[string]bool{StepType: true, StepModule: true, StepComponents: true, StepDatabase: true, StepPorts: true},
+			input: "none\n\n",
+			want: Answers{
+				Type:       "http",
+				Components: []string{"postgres", "redis", "node", "playwright"},
+				Ports:      tmpl.DefaultPorts(),
+				License:    "none",
+			},
+		},
+		{
+			title:   "Canceled at the summary",
+			input:   "cli\nexample.com/a\n\ncancel\n",
+			wantErr: ErrCanceled,
+		},
+		{
+			title:   "Stdin closed",
+			input:   "cli\n",
+			wantErr: errors.New("create-go-app: no answer to 'Module path, e.g. github.com/username/my-app' on stdin, pass it as a flag along with -yes"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			input, output := prompt.Input, prompt.Output
+			t.Cleanup(func() { prompt.Input, prompt.Output = input, output })
+			prompt.Input = bufio.NewReader(strings.NewReader(tt.input))
+			prompt.Output = &bytes.Buffer{}
+
+			w := testWizard(lineUI{})
+			w.Given = tt.given
+
+			a := testAnswers()
+			err := w.Run(context.Background(), a)
+			if tt.wantErr != nil {
+				if err == nil || err.Error() != tt.wantErr.Error() {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%v\n%s", err, prompt.Output)
+			}
+
+			assertAnswers(t, *a, tt.want)
+		})
+	}
+}
+
+func TestRunTerminal(t *testing.T) {
+	const (
+		up    = "\x1b[A"
+		down  = "\x1b[B"
+		left  = "\x1b[D"
+		enter = "\r"
+		space = " "
+	)
+
+	tests := []struct {
+		title   string
+		keys    string
+		want    Answers
+		wantErr error
+	}{
+		{
+			title: "Menus and input",
+			keys: down + enter +
+				"example.com/x" + enter +
+				up + enter +
+				enter,
+			want: Answers{
+				Type:    "cli",
+				Module:  "example.com/x",
+				Ports:   tmpl.DefaultPorts(),
+				License: "none",
+			},
+		},
+		{
+			title: "Back and editing",
+			// The module is typed with a typo, the license goes back to it
+			// and it is kept.
+			keys: down + enter +
+				"example.com/y" + "\x7f" + "x" + enter +
+				left +
+				enter +
+				down + enter +
+				enter,
+			want: Answers{
+				Type:    "cli",
+				Module:  "example.com/x",
+				Ports:   tmpl.DefaultPorts(),
+				License: "Apache-2.0",
+			},
+		},
+		{
+			title: "Multi select",
+			// Redis is deselected, postgres is dropped at the database step
+			// and the Go and Node ports are kept.
+			keys: enter +
+				"example.com/x" + enter +
+				space + enter +
+				down + enter +
+				enter + enter +
+				enter +
+				enter,
+			want: Answers{
+				Type:       "http",
+				Module:     "example.com/x",
+				Components: []string{"node", "playwright"},
+				Ports:      tmpl.DefaultPorts(),
+				License:    "MIT",
+			},
+		},
+		{
+			title: "Invalid selections are kept on screen",
+			// Deselecting node leaves playwright without its requirement.
+			keys: enter +
+				"example.com/x" + enter +
+				down + space + enter +
+				space + enter +
+				enter + enter + enter + enter + enter + enter + enter,
+			want: Answers{
+				Type:       "http",
+				Module:     "example.com/x",
+				Components: []string{"postgres", "redis", "node", "playwright"},
+				Ports:      tmpl.DefaultPorts(),
+				License:    "MIT",
+			},
+		},
+		{
+			title:   "Ctrl-C",
+			keys:    down + enter + "\x03",
+			wantErr: ErrCanceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			var out bytes.Buffer
+			w := testWizard(&terminal{in: bufio.NewReader(strings.NewReader(tt.keys)), out: &out})
+
+			a := testAnswers()
+			err := w.Run(context.Background(), a)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%v\n%q", err, out.String())
+			}
+
+			assertAnswers(t, *a, tt.want)
+		})
+	}
+}
+
+func TestSummary(t *testing.T) {
+	w := testWizard(nil)
+	a := testAnswers()
+	a.Module = "example.com/app"
+	a.Components = []string{"postgres", "node"}
+
+	want := []string{
+		"Type: http",
+		"Module: example.com/app",
+		"Components: node",
+		"Database: postgres",
+		"Ports: Go server 1111, Postgres 2222, Node client 7777",
+		"License: MIT",
+	}
+	if got := w.Summary(a); !slices.Equal(got, want) {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
+
+func assertAnswers(t *testing.T, got Answers, want Answers) {
+	t.Helper()
+
+	if got.Type != want.Type || got.Module != want.Module || got.License != want.License {
+		t.Errorf("type, module, license = %q, %q, %q, want %q, %q, %q", got.Type, got.Module, got.License, want.Type, want.Module, want.License)
+	}
+	if !slices.Equal(got.Components, want.Components) {
+		t.Errorf("components = %q, want %q", got.Components, want.Components)
+	}
+	if got.Ports != want.Ports {
+		t.Errorf("ports = %+v, want %+v", got.Ports, want.Ports)
+	}
+}